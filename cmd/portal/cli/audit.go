@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log",
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:     "verify [file...]",
+	Short:   "Check the audit log hash chain for tampering",
+	Example: "  portal audit verify\n  portal audit verify /var/log/portal/audit.log.20220501T101500.000000000 /var/log/portal/audit.log",
+	RunE:    auditVerify,
+}
+
+func init() {
+	auditCmd.AddCommand(auditVerifyCmd)
+}
+
+func auditVerify(_ *cobra.Command, args []string) error {
+	files := args
+	if len(files) == 0 {
+		path := viper.GetString("audit.path")
+		if path == "" {
+			return fmt.Errorf("no audit log configured")
+		}
+
+		var err error
+		files, err = portal.AuditFiles(path)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no audit log found at %s", path)
+		}
+	}
+
+	count, err := portal.VerifyAudit(files)
+	if err != nil {
+		return fmt.Errorf("audit log verification failed after %d records: %v", count, err)
+	}
+	log.Infof("Verified %d records in %d files, the chain is intact", count, len(files))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/apex/log"
 	jsonhandler "github.com/apex/log/handlers/json"
@@ -14,6 +15,7 @@ import (
 	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"storj.io/drpc"
 	"storj.io/drpc/drpcmux"
 	"storj.io/drpc/drpcserver"
 )
@@ -36,13 +38,62 @@ func Execute() {
 			certPath := viper.GetString("tls.cert")
 			keyPath := viper.GetString("tls.key")
 
-			m := drpcmux.New()
+			server := &portal.Server{
+				GracePeriod: viper.GetDuration("command.grace-period"),
+				Paths:       portal.NewPathPolicy(viper.GetStringSlice("file.allow"), viper.GetStringSlice("file.deny")),
+				MaxFileSize: viper.GetInt64("file.max-size"),
+			}
+
 			var err error
-			err = portalpb.DRPCRegisterPortal(m, &portal.Server{})
+
+			if policyPath := viper.GetString("command.policy"); policyPath != "" {
+				server.Policy, err = portal.LoadCommandPolicy(policyPath)
+				if err != nil {
+					return fmt.Errorf("could not load command policy: %v", err)
+				}
+				log.Infof("Loaded command policy from %s in %s mode", policyPath, server.Policy.Mode)
+			}
+
+			var authz portal.AuthzConfig
+			if err := viper.UnmarshalKey("authz", &authz); err != nil {
+				return fmt.Errorf("could not parse authorization config: %v", err)
+			}
+			if authz.Enabled {
+				if insecure {
+					log.Warn("Authorization is enabled but client certificates are not verified in insecure mode, every request will be denied")
+				}
+				server.Authz, err = portal.NewAuthorizer(authz)
+				if err != nil {
+					return fmt.Errorf("could not initialize authorization: %v", err)
+				}
+			}
+
+			spoolDir := viper.GetString("jobs.spool-dir")
+			jobs, err := portal.NewJobManager(spoolDir, server.GracePeriod, viper.GetDuration("jobs.retention"))
+			if err != nil {
+				log.Warnf("Couldn't initialize job spool at \"%s\", background jobs are disabled: %v", spoolDir, err)
+			} else {
+				server.Jobs = jobs
+			}
+
+			m := drpcmux.New()
+			err = portalpb.DRPCRegisterPortal(m, server)
 			if err != nil {
 				return fmt.Errorf("could not register DRPC server: %v", err)
 			}
-			s := drpcserver.New(m)
+
+			var handler drpc.Handler = m
+			if auditPath := viper.GetString("audit.path"); auditPath != "" {
+				audit, err := portal.OpenAuditLog(auditPath, viper.GetInt64("audit.max-size")*1024*1024)
+				if err != nil {
+					return fmt.Errorf("could not open audit log: %v", err)
+				}
+				defer audit.Close()
+				handler = audit.Handler(m)
+			} else {
+				log.Info("Audit log is disabled")
+			}
+			s := drpcserver.New(handler)
 
 			var tlsConfig *tls.Config
 			if insecure {
@@ -80,6 +131,15 @@ func Execute() {
 	rootCmd.Flags().String("ca", "ca.crt", "Path to the CA cert")
 	rootCmd.Flags().String("cert", "portal.crt", "Path to the server cert")
 	rootCmd.Flags().String("key", "portal.key", "Path to the server key")
+	rootCmd.Flags().Duration("grace-period", 5*time.Second, "Time a cancelled command is given to exit before it is killed")
+	rootCmd.Flags().String("command-policy", "", "Path to the policy file restricting which commands may run")
+	rootCmd.PersistentFlags().String("audit-log", "", "Path to the audit log, e.g. /var/log/portal/audit.log, empty disables auditing")
+	rootCmd.Flags().Int64("audit-max-size", 100, "Size in megabytes after which the audit log is rotated")
+	rootCmd.Flags().StringSlice("file-allow", []string{}, "Path prefixes clients may access, everything when empty")
+	rootCmd.Flags().StringSlice("file-deny", []string{"/etc/shadow", "/etc/shadow-", "/etc/gshadow", "/etc/gshadow-"}, "Path prefixes clients may never access")
+	rootCmd.Flags().Int64("file-max-size", 1024*1024, "Maximum number of bytes returned when reading a file, at most 3 MiB")
+	rootCmd.Flags().String("spool-dir", "/var/lib/portal/jobs", "Directory where background jobs keep their state and output")
+	rootCmd.Flags().Duration("job-retention", 7*24*time.Hour, "How long finished background jobs are kept, 0 keeps them forever")
 
 	viper.BindPFlag("tls.insecure", rootCmd.Flags().Lookup("insecure"))
 	viper.BindPFlag("tls.ca", rootCmd.Flags().Lookup("ca"))
@@ -89,11 +149,22 @@ func Execute() {
 	viper.BindPFlag("logging.json", rootCmd.PersistentFlags().Lookup("json"))
 	viper.BindPFlag("port", rootCmd.Flags().Lookup("port"))
 	viper.BindPFlag("address", rootCmd.Flags().Lookup("address"))
-
+	viper.BindPFlag("command.grace-period", rootCmd.Flags().Lookup("grace-period"))
+	viper.BindPFlag("command.policy", rootCmd.Flags().Lookup("command-policy"))
+	viper.BindPFlag("audit.path", rootCmd.PersistentFlags().Lookup("audit-log"))
+	viper.BindPFlag("audit.max-size", rootCmd.Flags().Lookup("audit-max-size"))
+	viper.BindPFlag("file.allow", rootCmd.Flags().Lookup("file-allow"))
+	viper.BindPFlag("file.deny", rootCmd.Flags().Lookup("file-deny"))
+	viper.BindPFlag("file.max-size", rootCmd.Flags().Lookup("file-max-size"))
+	viper.BindPFlag("jobs.spool-dir", rootCmd.Flags().Lookup("spool-dir"))
+	viper.BindPFlag("jobs.retention", rootCmd.Flags().Lookup("job-retention"))
+
+	rootCmd.AddCommand(auditCmd)
 	rootCmd.DisableSuggestions = false
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Error(err.Error())
+		os.Exit(1)
 	}
 }
 
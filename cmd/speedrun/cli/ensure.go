@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var ensureLineCmd = &cobra.Command{
+	Use:     "ensure-line <path> [line]",
+	Short:   "Make sure a line is present in a file, or absent with --absent",
+	Example: "  speedrun file ensure-line /etc/hosts '10.0.0.5 db.internal'\n  speedrun file ensure-line /etc/ssh/sshd_config 'PermitRootLogin no' --regexp '^#?PermitRootLogin'\n  speedrun file ensure-line /etc/hosts --absent --regexp 'db\\.internal$'",
+	Args:    cobra.RangeArgs(1, 2),
+	RunE:    ensure,
+}
+
+var ensureBlockCmd = &cobra.Command{
+	Use:     "ensure-block <path> <blockfile>",
+	Short:   "Make sure a block of lines between markers is present in a file, or absent with --absent",
+	Example: "  speedrun file ensure-block /etc/ssh/sshd_config ./sftp-match.conf\n  echo 'Banner /etc/issue' | speedrun file ensure-block /etc/ssh/sshd_config - --marker '# {mark} banner'",
+	Args:    cobra.RangeArgs(1, 2),
+	RunE:    ensure,
+}
+
+func init() {
+	ensureLineCmd.Flags().String("regexp", "", "Replace the last line matching this regular expression instead of adding a new one")
+	ensureBlockCmd.Flags().String("marker", "# {mark} SPEEDRUN MANAGED BLOCK", "Line surrounding the block, {mark} becomes BEGIN and END")
+	for _, c := range []*cobra.Command{ensureLineCmd, ensureBlockCmd} {
+		c.Flags().String("insert-after", "", "Insert after the last line matching this regular expression")
+		c.Flags().String("insert-before", "", "Insert before the first line matching this regular expression")
+		c.Flags().Bool("absent", false, "Remove instead of adding")
+		c.Flags().Bool("create", false, "Create the file if it doesn't exist")
+		c.Flags().Bool("backup", false, "Keep a timestamped copy of the file before changing it")
+	}
+}
+
+func ensure(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	insertAfter, err := cmd.Flags().GetString("insert-after")
+	if err != nil {
+		return err
+	}
+
+	insertBefore, err := cmd.Flags().GetString("insert-before")
+	if err != nil {
+		return err
+	}
+
+	absent, err := cmd.Flags().GetBool("absent")
+	if err != nil {
+		return err
+	}
+
+	create, err := cmd.Flags().GetBool("create")
+	if err != nil {
+		return err
+	}
+
+	backup, err := cmd.Flags().GetBool("backup")
+	if err != nil {
+		return err
+	}
+
+	for _, expr := range []string{insertAfter, insertBefore} {
+		if _, err := regexp.Compile(expr); err != nil {
+			return err
+		}
+	}
+
+	var lineReq *portalpb.FileEnsureLineRequest
+	var blockReq *portalpb.FileEnsureBlockRequest
+	switch cmd.Name() {
+	case "ensure-line":
+		match, err := cmd.Flags().GetString("regexp")
+		if err != nil {
+			return err
+		}
+		if _, err := regexp.Compile(match); err != nil {
+			return err
+		}
+		var line string
+		switch {
+		case len(args) == 2:
+			line = args[1]
+		case !absent || match == "":
+			return fmt.Errorf("ensure-line requires a line unless --absent and --regexp are set")
+		}
+		lineReq = &portalpb.FileEnsureLineRequest{
+			Path:         args[0],
+			Line:         line,
+			Regexp:       match,
+			InsertAfter:  insertAfter,
+			InsertBefore: insertBefore,
+			Absent:       absent,
+			Create:       create,
+			Backup:       backup,
+		}
+	case "ensure-block":
+		marker, err := cmd.Flags().GetString("marker")
+		if err != nil {
+			return err
+		}
+		var block []byte
+		switch {
+		case len(args) < 2 && !absent:
+			return fmt.Errorf("ensure-block requires a block file or - unless --absent is set")
+		case len(args) < 2:
+		case args[1] == "-":
+			block, err = io.ReadAll(os.Stdin)
+		default:
+			block, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			return err
+		}
+		blockReq = &portalpb.FileEnsureBlockRequest{
+			Path:         args[0],
+			Block:        string(block),
+			Marker:       marker,
+			InsertAfter:  insertAfter,
+			InsertBefore: insertBefore,
+			Absent:       absent,
+			Create:       create,
+			Backup:       backup,
+		}
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			var r *portalpb.FileEditResponse
+			if lineReq != nil {
+				r, err = c.FileEnsureLine(ctx, lineReq)
+			} else {
+				r, err = c.FileEnsureBlock(ctx, blockReq)
+			}
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", r.GetState()).Info(r.GetMessage())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
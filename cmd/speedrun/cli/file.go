@@ -33,6 +33,21 @@ var readCmd = &cobra.Command{
 func init() {
 	fileCmd.SetUsageTemplate(usage)
 	fileCmd.AddCommand(readCmd)
+	fileCmd.AddCommand(pushCmd)
+	fileCmd.AddCommand(pullCmd)
+	fileCmd.AddCommand(tailCmd)
+	fileCmd.AddCommand(grepCmd)
+	fileCmd.AddCommand(statCmd)
+	fileCmd.AddCommand(checksumCmd)
+	fileCmd.AddCommand(templateCmd)
+	fileCmd.AddCommand(syncCmd)
+	fileCmd.AddCommand(ensureLineCmd)
+	fileCmd.AddCommand(ensureBlockCmd)
+	fileCmd.AddCommand(chmodCmd)
+	fileCmd.AddCommand(chownCmd)
+	fileCmd.AddCommand(mkdirCmd)
+	fileCmd.AddCommand(rmCmd)
+	fileCmd.AddCommand(mvCmd)
 }
 
 func read(cmd *cobra.Command, args []string) error {
@@ -53,6 +68,7 @@ func read(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	dialer := &tls.Dialer{Config: tlsConfig}
 	pool := pond.New(1000, 10000)
 	for _, p := range portals {
 		portal := p
@@ -63,8 +79,11 @@ func read(cmd *cobra.Command, args []string) error {
 			}
 			log := log.WithFields(fields)
 
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
 			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
-			rawconn, err := tls.Dial("tcp", addr, tlsConfig)
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
 			if err != nil {
 				log.Error(err.Error())
 				return
@@ -74,8 +93,6 @@ func read(cmd *cobra.Command, args []string) error {
 			defer conn.Close()
 
 			c := portalpb.NewDRPCPortalClient(conn)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
 
 			path := strings.Join(args, " ")
 			r, err := c.FileRead(ctx, &portalpb.FileReadRequest{Path: path})
@@ -83,7 +100,16 @@ func read(cmd *cobra.Command, args []string) error {
 				log.Error(err.Error())
 				return
 			}
-			log.WithField("state", r.GetState()).Infof("Contents of %s:\n%s", path, r.GetContent())
+			log = log.WithField("state", r.GetState())
+			if r.GetTruncated() {
+				log = log.WithField("truncated", true)
+				log.Warnf("File is %d bytes, only the first %d were returned", r.GetSize(), len(r.GetContent()))
+			}
+			if r.GetBinary() {
+				log.Infof("%s is a binary file of %d bytes, not printing its contents", path, r.GetSize())
+				return
+			}
+			log.Infof("Contents of %s:\n%s", path, r.GetContent())
 
 		})
 	}
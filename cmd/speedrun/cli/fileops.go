@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var chmodCmd = &cobra.Command{
+	Use:     "chmod <mode> <path>",
+	Short:   "Change the mode of a file or directory",
+	Example: "  speedrun file chmod 0640 /etc/app/secret.conf\n  speedrun file chmod -R --force 0755 /opt/app/bin",
+	Args:    cobra.ExactArgs(2),
+	RunE:    fileOp,
+}
+
+var chownCmd = &cobra.Command{
+	Use:     "chown <owner[:group]> <path>",
+	Short:   "Change the owner and group of a file or directory",
+	Example: "  speedrun file chown www-data:www-data /var/www/index.html\n  speedrun file chown -R --force app /srv/app",
+	Args:    cobra.ExactArgs(2),
+	RunE:    fileOp,
+}
+
+var mkdirCmd = &cobra.Command{
+	Use:     "mkdir <path>",
+	Short:   "Create a directory",
+	Example: "  speedrun file mkdir -p /srv/app/releases --mode 0750 --owner app",
+	Args:    cobra.ExactArgs(1),
+	RunE:    fileOp,
+}
+
+var rmCmd = &cobra.Command{
+	Use:     "rm <path>",
+	Short:   "Remove a file or directory",
+	Example: "  speedrun file rm /tmp/debug.log\n  speedrun file rm -r --force /srv/app/releases/old",
+	Args:    cobra.ExactArgs(1),
+	RunE:    fileOp,
+}
+
+var mvCmd = &cobra.Command{
+	Use:     "mv <source> <destination>",
+	Short:   "Move or rename a file or directory",
+	Example: "  speedrun file mv /etc/app/app.conf /etc/app/app.conf.disabled",
+	Args:    cobra.ExactArgs(2),
+	RunE:    fileOp,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{chmodCmd, chownCmd, rmCmd} {
+		c.Flags().BoolP("recursive", "R", false, "Operate on directories and their contents, requires --force")
+		c.Flags().Bool("force", false, "Confirm a recursive operation")
+	}
+	rmCmd.Flags().Lookup("recursive").Shorthand = "r"
+	mkdirCmd.Flags().BoolP("parents", "p", false, "Create missing parent directories")
+	mkdirCmd.Flags().String("mode", "", "Octal directory mode, 0755 by default")
+	mkdirCmd.Flags().String("owner", "", "Owner of the directory, name or uid")
+	mkdirCmd.Flags().String("group", "", "Group of the directory, name or gid")
+	mvCmd.Flags().Bool("overwrite", false, "Replace the destination if it exists")
+}
+
+func fileOp(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	var recursive, force bool
+	if f := cmd.Flags().Lookup("recursive"); f != nil {
+		recursive, err = cmd.Flags().GetBool("recursive")
+		if err != nil {
+			return err
+		}
+		force, err = cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+		if recursive && !force {
+			return fmt.Errorf("recursive %s requires --force", cmd.Name())
+		}
+	}
+
+	var call func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error)
+	switch cmd.Name() {
+	case "chmod":
+		mode, err := strconv.ParseUint(args[0], 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode: %s", args[0])
+		}
+		req := &portalpb.FileChmodRequest{Path: args[1], Mode: uint32(mode), Recursive: recursive, Force: force}
+		call = func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error) {
+			r, err := c.FileChmod(ctx, req)
+			return r.GetState(), r.GetMessage(), err
+		}
+	case "chown":
+		owner, group, _ := strings.Cut(args[0], ":")
+		req := &portalpb.FileChownRequest{Path: args[1], Owner: owner, Group: group, Recursive: recursive, Force: force}
+		call = func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error) {
+			r, err := c.FileChown(ctx, req)
+			return r.GetState(), r.GetMessage(), err
+		}
+	case "mkdir":
+		req := &portalpb.FileMkdirRequest{Path: args[0]}
+		if err := applyMkdirFlags(cmd, req); err != nil {
+			return err
+		}
+		call = func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error) {
+			r, err := c.FileMkdir(ctx, req)
+			return r.GetState(), r.GetMessage(), err
+		}
+	case "rm":
+		req := &portalpb.FileRemoveRequest{Path: args[0], Recursive: recursive, Force: force}
+		call = func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error) {
+			r, err := c.FileRemove(ctx, req)
+			return r.GetState(), r.GetMessage(), err
+		}
+	case "mv":
+		overwrite, err := cmd.Flags().GetBool("overwrite")
+		if err != nil {
+			return err
+		}
+		req := &portalpb.FileMoveRequest{Source: args[0], Destination: args[1], Overwrite: overwrite}
+		call = func(ctx context.Context, c portalpb.DRPCPortalClient) (portalpb.State, string, error) {
+			r, err := c.FileMove(ctx, req)
+			return r.GetState(), r.GetMessage(), err
+		}
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*30)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			state, msg, err := call(ctx, c)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", state).Info(msg)
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+func applyMkdirFlags(cmd *cobra.Command, req *portalpb.FileMkdirRequest) error {
+	var err error
+	if req.Parents, err = cmd.Flags().GetBool("parents"); err != nil {
+		return err
+	}
+	if req.Owner, err = cmd.Flags().GetString("owner"); err != nil {
+		return err
+	}
+	if req.Group, err = cmd.Flags().GetString("group"); err != nil {
+		return err
+	}
+
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return err
+	}
+	if mode != "" {
+		m, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || m > 0777 {
+			return fmt.Errorf("invalid mode: %s", mode)
+		}
+		req.Mode = uint32(m)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"storj.io/drpc/drpcconn"
+)
+
+// fleet keeps one drpc connection per portal open so that commands issued
+// one after another don't pay for a new TLS handshake every time. Broken
+// connections are redialed on next use.
+type fleet struct {
+	dialer       *tls.Dialer
+	usePrivateIP bool
+
+	mu    sync.Mutex
+	conns map[string]*drpcconn.Conn
+}
+
+func newFleet(tlsConfig *tls.Config, usePrivateIP bool) *fleet {
+	return &fleet{
+		dialer:       &tls.Dialer{Config: tlsConfig},
+		usePrivateIP: usePrivateIP,
+		conns:        map[string]*drpcconn.Conn{},
+	}
+}
+
+// client returns a portal client for the instance, dialing it if there is
+// no usable connection yet. A drpc connection runs one RPC at a time so
+// callers must not share a client between goroutines.
+func (f *fleet) client(ctx context.Context, instance cloud.Instance) (portalpb.DRPCPortalClient, error) {
+	addr := net.JoinHostPort(instance.GetAddress(f.usePrivateIP), "1337")
+
+	f.mu.Lock()
+	conn, ok := f.conns[addr]
+	f.mu.Unlock()
+
+	if ok {
+		select {
+		case <-conn.Closed():
+		default:
+			return portalpb.NewDRPCPortalClient(conn), nil
+		}
+	}
+
+	rawconn, err := f.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn = drpcconn.New(rawconn)
+
+	f.mu.Lock()
+	f.conns[addr] = conn
+	f.mu.Unlock()
+
+	return portalpb.NewDRPCPortalClient(conn), nil
+}
+
+// Close closes all open connections.
+func (f *fleet) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for addr, conn := range f.conns {
+		conn.Close()
+		delete(f.conns, addr)
+	}
+}
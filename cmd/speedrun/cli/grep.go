@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var grepCmd = &cobra.Command{
+	Use:     "grep <pattern> <path-glob>",
+	Short:   "Search files for lines matching a regular expression",
+	Example: "  speedrun file grep 'out of memory' '/var/log/*.log' -C 2\n  speedrun file grep -l -i 'segfault' /var/log/syslog",
+	Args:    cobra.ExactArgs(2),
+	RunE:    grepFiles,
+}
+
+func init() {
+	grepCmd.Flags().Int32P("context", "C", 0, "Number of lines to print around each match")
+	grepCmd.Flags().Int32P("max-count", "m", 100, "Maximum number of matches per host")
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Match case insensitively")
+	grepCmd.Flags().BoolP("hosts", "l", false, "Only list the hosts with matches")
+}
+
+func grepFiles(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	contextLines, err := cmd.Flags().GetInt32("context")
+	if err != nil {
+		return err
+	}
+
+	maxCount, err := cmd.Flags().GetInt32("max-count")
+	if err != nil {
+		return err
+	}
+
+	ignoreCase, err := cmd.Flags().GetBool("ignore-case")
+	if err != nil {
+		return err
+	}
+
+	hostsOnly, err := cmd.Flags().GetBool("hosts")
+	if err != nil {
+		return err
+	}
+
+	if _, err := regexp.Compile(args[0]); err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	req := &portalpb.FileGrepRequest{
+		Pattern:    args[0],
+		Path:       args[1],
+		Context:    contextLines,
+		MaxMatches: maxCount,
+		IgnoreCase: ignoreCase,
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*30)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := c.FileGrep(ctx, req)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			if r.GetMatches() == 0 {
+				log.Debug("No matches")
+				return
+			}
+			if r.GetTruncated() {
+				log.Warnf("Stopped after %d matches", r.GetMatches())
+			}
+			if hostsOnly {
+				log.Infof("%d matches", r.GetMatches())
+				return
+			}
+			printGrepLines(portal.Name, r.GetLines())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// printGrepLines prints the lines of a host in one go, separating groups of
+// lines that aren't adjacent with -- like grep does.
+func printGrepLines(host string, lines []*portalpb.GrepLine) {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+
+	var prev *portalpb.GrepLine
+	for _, l := range lines {
+		if prev != nil && (prev.GetPath() != l.GetPath() || prev.GetNumber()+1 != l.GetNumber()) {
+			fmt.Fprintf(os.Stdout, "[%s] --\n", host)
+		}
+		sep := "-"
+		if l.GetMatch() {
+			sep = ":"
+		}
+		fmt.Fprintf(os.Stdout, "[%s] %s%s%d%s%s\n", host, l.GetPath(), sep, l.GetNumber(), sep, l.GetText())
+		prev = l
+	}
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var jobCmd = &cobra.Command{
+	Use:              "job",
+	Short:            "Manage long running background jobs",
+	TraverseChildren: true,
+}
+
+var jobSubmitCmd = &cobra.Command{
+	Use:     "submit <command to run>",
+	Short:   "Start a command in the background",
+	Example: "  speedrun job submit /usr/local/bin/backup.sh --full\n  speedrun job submit --id reindex-42 reindexer --all",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    jobAction,
+}
+
+var jobStatusCmd = &cobra.Command{
+	Use:     "status <id>",
+	Short:   "Return the status of a job",
+	Example: "  speedrun job status 3f2a9c1b7d4e8f60",
+	Args:    cobra.ExactArgs(1),
+	RunE:    jobAction,
+}
+
+var jobLogsCmd = &cobra.Command{
+	Use:     "logs <id>",
+	Short:   "Fetch the output of a job",
+	Example: "  speedrun job logs 3f2a9c1b7d4e8f60\n  speedrun job logs 3f2a9c1b7d4e8f60 --follow",
+	Args:    cobra.ExactArgs(1),
+	RunE:    jobAction,
+}
+
+var jobCancelCmd = &cobra.Command{
+	Use:     "cancel <id>",
+	Short:   "Cancel a running job",
+	Example: "  speedrun job cancel 3f2a9c1b7d4e8f60",
+	Args:    cobra.ExactArgs(1),
+	RunE:    jobAction,
+}
+
+var jobListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List jobs",
+	Example: "  speedrun job list",
+	Args:    cobra.NoArgs,
+	RunE:    jobAction,
+}
+
+func init() {
+	jobCmd.SetUsageTemplate(usage)
+	jobCmd.AddCommand(jobSubmitCmd)
+	jobCmd.AddCommand(jobStatusCmd)
+	jobCmd.AddCommand(jobLogsCmd)
+	jobCmd.AddCommand(jobCancelCmd)
+	jobCmd.AddCommand(jobListCmd)
+
+	jobSubmitCmd.Flags().SetInterspersed(false)
+	jobSubmitCmd.Flags().String("id", "", "Job ID to use on every host, generated when empty")
+	jobLogsCmd.Flags().BoolP("follow", "f", false, "Keep streaming output until the job finishes")
+}
+
+func jobAction(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	var id string
+	var follow bool
+	switch cmd.Name() {
+	case "submit":
+		id, err = cmd.Flags().GetString("id")
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			b := make([]byte, 8)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			id = hex.EncodeToString(b)
+		}
+	case "logs":
+		follow, err = cmd.Flags().GetBool("follow")
+		if err != nil {
+			return err
+		}
+		id = args[0]
+	case "status", "cancel":
+		id = args[0]
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Name() == "submit" {
+		log.Infof("Submitting job %s", id)
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			// Following logs lasts as long as the job does.
+			var ctx context.Context
+			var cancel context.CancelFunc
+			if follow {
+				ctx, cancel = context.WithCancel(cmd.Context())
+			} else {
+				ctx, cancel = context.WithTimeout(cmd.Context(), time.Second*10)
+			}
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			switch cmd.Name() {
+			case "submit":
+				r, err := c.JobSubmit(ctx, &portalpb.JobSubmitRequest{Id: id, Name: args[0], Args: args[1:]})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				log.WithField("state", r.GetState()).Infof("Job %s submitted", r.GetId())
+			case "status":
+				r, err := c.JobStatus(ctx, &portalpb.JobRequest{Id: id})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				logJob(log, r.GetJob())
+			case "logs":
+				stream, err := c.JobLogs(ctx, &portalpb.JobLogsRequest{Id: id, Follow: follow})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				w := newHostWriter(portal.Name)
+				defer w.Flush()
+				for {
+					r, err := stream.Recv()
+					if errors.Is(err, io.EOF) {
+						return
+					}
+					if err != nil {
+						log.Error(err.Error())
+						return
+					}
+					w.Write(r.GetData())
+				}
+			case "cancel":
+				r, err := c.JobCancel(ctx, &portalpb.JobRequest{Id: id})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				log.WithField("state", r.GetState()).Info(r.GetMessage())
+			case "list":
+				r, err := c.JobList(ctx, &portalpb.JobListRequest{})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				if len(r.GetJobs()) == 0 {
+					log.Info("No jobs")
+				}
+				for _, job := range r.GetJobs() {
+					logJob(log, job)
+				}
+			}
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+func logJob(entry *log.Entry, job *portalpb.Job) {
+	started := time.Unix(job.GetStartedAt(), 0)
+	fields := entry.WithField("id", job.GetId()).WithField("started", started.Format(time.RFC3339))
+
+	switch job.GetState() {
+	case portalpb.JobState_PENDING, portalpb.JobState_RUNNING:
+		fields = fields.WithField("running", time.Since(started).Round(time.Second))
+	case portalpb.JobState_LOST:
+		fields = fields.WithField("error", job.GetError())
+	default:
+		finished := time.Unix(job.GetFinishedAt(), 0)
+		fields = fields.WithField("exitcode", job.GetExitCode()).WithField("took", finished.Sub(started))
+		if job.GetError() != "" {
+			fields = fields.WithField("error", job.GetError())
+		}
+	}
+
+	command := strings.TrimSpace(job.GetName() + " " + strings.Join(job.GetArgs(), " "))
+	fields.Infof("%s: %s", job.GetState(), command)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var stdoutMu sync.Mutex
+
+// hostWriter prefixes every line written to it with the host name. Lines
+// coming from different hosts are never interleaved.
+type hostWriter struct {
+	host string
+	buf  []byte
+}
+
+func newHostWriter(host string) *hostWriter {
+	return &hostWriter{host: host}
+}
+
+func (w *hostWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.printLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush prints whatever is left of an unterminated last line.
+func (w *hostWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.printLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *hostWriter) printLine(line []byte) {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	fmt.Fprintf(os.Stdout, "[%s] %s\n", w.host, line)
+}
+
+// hostResult is the outcome of an action on a single host.
+type hostResult struct {
+	host   string
+	output string
+	err    error
+}
+
+// printGroupedResults prints hosts with identical output together, the
+// largest group first.
+func printGroupedResults(results []hostResult) {
+	type group struct {
+		hosts  []string
+		output string
+		failed bool
+	}
+
+	groups := map[string]*group{}
+	for _, r := range results {
+		key, output, failed := "ok:"+r.output, r.output, false
+		if r.err != nil {
+			key, output, failed = "error:"+r.err.Error(), r.err.Error(), true
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{output: output, failed: failed}
+			groups[key] = g
+		}
+		g.hosts = append(g.hosts, r.host)
+	}
+
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.hosts)
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		if len(sorted[a].hosts) != len(sorted[b].hosts) {
+			return len(sorted[a].hosts) > len(sorted[b].hosts)
+		}
+		return sorted[a].hosts[0] < sorted[b].hosts[0]
+	})
+
+	for _, g := range sorted {
+		hosts := g.hosts
+		more := ""
+		if len(hosts) > 10 {
+			more = fmt.Sprintf(" and %d more", len(hosts)-10)
+			hosts = hosts[:10]
+		}
+		status := ""
+		if g.failed {
+			status = " failed"
+		}
+		noun := "hosts"
+		if len(g.hosts) == 1 {
+			noun = "host"
+		}
+		fmt.Printf("--- %d %s%s: %s%s\n", len(g.hosts), noun, status, strings.Join(hosts, ", "), more)
+		if g.output != "" {
+			fmt.Println(g.output)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var pullCmd = &cobra.Command{
+	Use:     "pull <remote> <localdir>",
+	Short:   "Download files from every host into <localdir>/<host>",
+	Example: "  speedrun file pull /var/log/app.log ./logs\n  speedrun file pull '/var/crash/*' ./cores",
+	Args:    cobra.ExactArgs(2),
+	RunE:    pull,
+}
+
+func init() {
+	pullCmd.Flags().Duration("timeout", 10*time.Minute, "Time to wait for the download to finish before cancelling it")
+}
+
+func pull(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	remote, localDir := args[0], args[1]
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			dir := filepath.Join(localDir, portal.Name)
+			msg, err := pullFiles(ctx, c, remote, dir)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.Info(msg)
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// pullFiles downloads remote into dir. Single files are first written to a
+// .part file which is picked up again by the next attempt, provided the
+// remote file still starts with the same bytes.
+func pullFiles(ctx context.Context, c portalpb.DRPCPortalClient, remote, dir string) (string, error) {
+	partial := filepath.Join(dir, filepath.Base(remote)+".part")
+	req := &portalpb.FilePullRequest{Path: remote}
+	if f, err := os.Open(partial); err == nil {
+		h := sha256.New()
+		n, err := io.Copy(h, f)
+		f.Close()
+		if err == nil {
+			req.Offset = n
+			req.PrefixSha256 = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+
+	stream, err := c.FilePull(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	header, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	r := &pullReader{stream: stream}
+
+	if header.GetArchive() {
+		n, err := extractTar(r, dir)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Pulled %d files into %s", n, dir), nil
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if header.GetOffset() == 0 {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(partial, flags, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Seek(header.GetOffset(), io.SeekStart); err != nil {
+		return "", err
+	}
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return "", err
+	}
+	if got := header.GetOffset() + n; got != header.GetSize() {
+		// Keep the partial copy around for the next attempt.
+		return "", fmt.Errorf("download incomplete, got %d of %d bytes", got, header.GetSize())
+	}
+	if err := f.Sync(); err != nil {
+		return "", err
+	}
+	if err := f.Chmod(os.FileMode(header.GetMode()).Perm()); err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, header.GetName())
+	if err := os.Rename(partial, name); err != nil {
+		return "", err
+	}
+	if header.GetOffset() > 0 {
+		return fmt.Sprintf("Resumed %s at %d bytes, pulled %d more", name, header.GetOffset(), n), nil
+	}
+	return fmt.Sprintf("Pulled %s (%d bytes)", name, n), nil
+}
+
+// extractTar unpacks regular files and directories from r below dir and
+// returns the number of files written.
+func extractTar(r io.Reader, dir string) (int, error) {
+	count := 0
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return count, fmt.Errorf("refusing to extract %s outside of %s", hdr.Name, dir)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return count, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return count, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return count, err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+}
+
+// pullReader reads the data of a FilePull stream until the portal is done
+// sending.
+type pullReader struct {
+	stream portalpb.DRPCPortal_FilePullClient
+	buf    []byte
+}
+
+func (r *pullReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = msg.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
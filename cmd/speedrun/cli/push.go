@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+// pushChunkSize is the amount of file content sent per FileWrite message.
+const pushChunkSize = 64 * 1024
+
+var pushCmd = &cobra.Command{
+	Use:     "push <local> <remote>",
+	Short:   "Upload a file",
+	Example: "  speedrun file push ./ntp.conf /etc/ntp.conf --mode 0644 --owner root --backup",
+	Args:    cobra.ExactArgs(2),
+	RunE:    push,
+}
+
+func init() {
+	addWriteFlags(pushCmd, "Octal file mode, defaults to the mode of the local file")
+	pushCmd.Flags().Duration("timeout", time.Minute, "Time to wait for the upload to finish before cancelling it")
+}
+
+// addWriteFlags adds the flags controlling how remote files are written.
+func addWriteFlags(cmd *cobra.Command, modeUsage string) {
+	cmd.Flags().String("mode", "", modeUsage)
+	cmd.Flags().String("owner", "", "Owner of the remote file, name or uid")
+	cmd.Flags().String("group", "", "Group of the remote file, name or gid")
+	cmd.Flags().BoolP("parents", "p", false, "Create missing parent directories")
+	cmd.Flags().Bool("backup", false, "Keep a timestamped copy of the file being replaced")
+}
+
+func push(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	header, err := pushHeader(cmd, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("sha256", header.GetSha256()).Infof("Pushing %s to %s", args[0], args[1])
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := pushFile(ctx, c, header, func() (io.ReadCloser, error) {
+				return os.Open(args[0])
+			})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", r.GetState()).Info(r.GetMessage())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// pushHeader builds the first FileWrite message for uploading local to
+// remote from the command line flags.
+func pushHeader(cmd *cobra.Command, local, remote string) (*portalpb.FileWriteRequest, error) {
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", local)
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &portalpb.FileWriteRequest{
+		Path:   remote,
+		Mode:   uint32(info.Mode().Perm()),
+		Sha256: hex.EncodeToString(h.Sum(nil)),
+		Size:   size,
+	}
+	if err := applyWriteFlags(cmd, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// applyWriteFlags sets the metadata fields of header from the flags added by
+// addWriteFlags.
+func applyWriteFlags(cmd *cobra.Command, header *portalpb.FileWriteRequest) error {
+	mode, err := cmd.Flags().GetString("mode")
+	if err != nil {
+		return err
+	}
+	if mode != "" {
+		m, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || m > 0777 {
+			return fmt.Errorf("invalid mode: %s", mode)
+		}
+		header.Mode = uint32(m)
+	}
+
+	if header.Owner, err = cmd.Flags().GetString("owner"); err != nil {
+		return err
+	}
+	if header.Group, err = cmd.Flags().GetString("group"); err != nil {
+		return err
+	}
+	if header.Parents, err = cmd.Flags().GetBool("parents"); err != nil {
+		return err
+	}
+	if header.Backup, err = cmd.Flags().GetBool("backup"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// pushFile sends header to the portal and, unless the remote file is already
+// up to date, streams the content returned by open after it.
+func pushFile(ctx context.Context, c portalpb.DRPCPortalClient, header *portalpb.FileWriteRequest, open func() (io.ReadCloser, error)) (*portalpb.FileWriteResponse, error) {
+	stream, err := c.FileWrite(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	if err := stream.Send(header); err != nil {
+		return nil, err
+	}
+	r, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if !r.GetReady() {
+		return r, nil
+	}
+
+	content, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	buf := make([]byte, pushChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&portalpb.FileWriteRequest{Chunk: buf[:n]}); err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return stream.Recv()
+}
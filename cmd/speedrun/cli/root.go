@@ -1,10 +1,13 @@
 package cli
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/apex/log"
 	jsonhandler "github.com/apex/log/handlers/json"
@@ -36,7 +39,7 @@ func Execute() {
 
 	cobra.OnInitialize(initConfig)
 	rootCmd.SetUsageTemplate(rootUsage)
-	rootCmd.AddCommand(runCmd, serviceCmd, fileCmd, systemCmd)
+	rootCmd.AddCommand(runCmd, scriptCmd, shellCmd, serviceCmd, unitCmd, fileCmd, systemCmd, jobCmd, topCmd)
 
 	home, err := homedir.Dir()
 	if err != nil {
@@ -66,7 +69,15 @@ func Execute() {
 
 	rootCmd.DisableSuggestions = false
 
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels every in-flight request, a second one kills speedrun right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		log.Error(err.Error())
 	}
 }
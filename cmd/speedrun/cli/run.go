@@ -27,6 +27,7 @@ var runCmd = &cobra.Command{
 
 func init() {
 	runCmd.SetUsageTemplate(usage)
+	runCmd.Flags().Duration("timeout", 10*time.Second, "Time to wait for the command to finish before cancelling it")
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -44,11 +45,17 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
 	portals, err := cloud.GetInstances(target)
 	if err != nil {
 		return err
 	}
 
+	dialer := &tls.Dialer{Config: tlsConfig}
 	pool := pond.New(1000, 10000)
 	for _, p := range portals {
 		portal := p
@@ -59,8 +66,11 @@ func run(cmd *cobra.Command, args []string) error {
 			}
 			log := log.WithFields(fields)
 
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
 			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
-			rawconn, err := tls.Dial("tcp", addr, tlsConfig)
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
 			if err != nil {
 				log.Error(err.Error())
 				return
@@ -70,8 +80,6 @@ func run(cmd *cobra.Command, args []string) error {
 			defer conn.Close()
 
 			c := portalpb.NewDRPCPortalClient(conn)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
 
 			r, err := c.RunCommand(ctx, &portalpb.CommandRequest{Name: s[0], Args: s[1:]})
 			if err != nil {
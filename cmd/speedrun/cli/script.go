@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var scriptCmd = &cobra.Command{
+	Use:     "script <path> [args]",
+	Short:   "Upload and run a local script on remote servers",
+	Example: "  speedrun script ./fix-ntp.sh\n  speedrun script ./fix-ntp.sh --args pool.ntp.org --args --force",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    script,
+}
+
+func init() {
+	scriptCmd.SetUsageTemplate(usage)
+	scriptCmd.Flags().StringArray("args", []string{}, "Argument to pass to the script, can be repeated")
+	scriptCmd.Flags().Duration("timeout", 10*time.Second, "Time to wait for the script to finish before cancelling it")
+}
+
+func script(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	scriptArgs, err := cmd.Flags().GetStringArray("args")
+	if err != nil {
+		return err
+	}
+	scriptArgs = append(scriptArgs, args[1:]...)
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	name := filepath.Base(args[0])
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("sha256", checksum).Infof("Running script %s", name)
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := c.RunScript(ctx, &portalpb.ScriptRequest{Name: name, Content: content, Args: scriptArgs, Sha256: checksum})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", r.GetState()).Info(r.GetMessage())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
@@ -3,7 +3,9 @@ package cli
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
+	"net/url"
 	"strings"
 	"time"
 
@@ -25,7 +27,7 @@ var serviceCmd = &cobra.Command{
 var restartCmd = &cobra.Command{
 	Use:     "restart <servicename>",
 	Short:   "Restart a service",
-	Example: "  speedrun service restart nginx",
+	Example: "  speedrun service restart nginx\n  speedrun service restart nginx --wait-active --wait-healthy http://localhost/healthz",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    action,
 }
@@ -33,7 +35,7 @@ var restartCmd = &cobra.Command{
 var startCmd = &cobra.Command{
 	Use:     "start <servicename>",
 	Short:   "Start a service",
-	Example: "  speedrun service start nginx",
+	Example: "  speedrun service start nginx\n  speedrun service start backup.timer",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    action,
 }
@@ -49,17 +51,83 @@ var stopCmd = &cobra.Command{
 var statusCmd = &cobra.Command{
 	Use:     "status <servicename>",
 	Short:   "Return the status of the service",
-	Example: "  speedrun service status nginx",
+	Example: "  speedrun service status nginx\n  speedrun service status app --sort restarts",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    serviceStatus,
+}
+
+var enableCmd = &cobra.Command{
+	Use:     "enable <servicename>",
+	Short:   "Enable a service to start at boot",
+	Example: "  speedrun service enable nginx\n  speedrun service enable fstrim.timer",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    action,
+}
+
+var disableCmd = &cobra.Command{
+	Use:     "disable <servicename>",
+	Short:   "Disable a service from starting at boot",
+	Example: "  speedrun service disable nginx",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    action,
+}
+
+var maskCmd = &cobra.Command{
+	Use:     "mask <servicename>",
+	Short:   "Mask a service so it can't be started",
+	Example: "  speedrun service mask nginx",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    action,
+}
+
+var unmaskCmd = &cobra.Command{
+	Use:     "unmask <servicename>",
+	Short:   "Unmask a service",
+	Example: "  speedrun service unmask nginx",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    action,
+}
+
+var reloadCmd = &cobra.Command{
+	Use:     "reload <servicename>",
+	Short:   "Reload the configuration of a service",
+	Example: "  speedrun service reload nginx",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    action,
 }
 
+var daemonReloadCmd = &cobra.Command{
+	Use:     "daemon-reload",
+	Short:   "Reload the systemd manager configuration",
+	Example: "  speedrun service daemon-reload",
+	Args:    cobra.NoArgs,
+	RunE:    action,
+}
+
 func init() {
 	serviceCmd.SetUsageTemplate(usage)
 	serviceCmd.AddCommand(restartCmd)
 	serviceCmd.AddCommand(startCmd)
 	serviceCmd.AddCommand(stopCmd)
 	serviceCmd.AddCommand(statusCmd)
+	serviceCmd.AddCommand(enableCmd)
+	serviceCmd.AddCommand(disableCmd)
+	serviceCmd.AddCommand(maskCmd)
+	serviceCmd.AddCommand(unmaskCmd)
+	serviceCmd.AddCommand(reloadCmd)
+	serviceCmd.AddCommand(daemonReloadCmd)
+	serviceCmd.AddCommand(serviceListCmd)
+	serviceCmd.AddCommand(serviceLogsCmd)
+	serviceCmd.AddCommand(installCmd)
+	serviceCmd.AddCommand(overrideCmd)
+
+	for _, c := range []*cobra.Command{restartCmd, startCmd, stopCmd} {
+		c.Flags().Bool("wait-active", false, "Wait until the unit is active, or inactive when stopping")
+		c.Flags().Duration("wait-timeout", 30*time.Second, "How long to wait for the unit")
+	}
+	for _, c := range []*cobra.Command{restartCmd, startCmd} {
+		c.Flags().String("wait-healthy", "", "Wait until a probe of the host itself passes, tcp://localhost:port or an http(s) URL on the host")
+	}
 }
 
 func action(cmd *cobra.Command, args []string) error {
@@ -75,11 +143,40 @@ func action(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	req := &portalpb.ServiceRequest{Name: strings.Join(args, " ")}
+	timeout := time.Second * 10
+	if cmd.Flags().Lookup("wait-active") != nil {
+		if req.WaitActive, err = cmd.Flags().GetBool("wait-active"); err != nil {
+			return err
+		}
+		waitTimeout, err := cmd.Flags().GetDuration("wait-timeout")
+		if err != nil {
+			return err
+		}
+		req.WaitTimeout = int32(waitTimeout.Seconds())
+		timeout += waitTimeout
+	}
+	if cmd.Flags().Lookup("wait-healthy") != nil {
+		if req.HealthCheck, err = cmd.Flags().GetString("wait-healthy"); err != nil {
+			return err
+		}
+		if req.HealthCheck != "" {
+			u, err := url.Parse(req.HealthCheck)
+			if err != nil {
+				return err
+			}
+			if u.Scheme != "tcp" && u.Scheme != "http" && u.Scheme != "https" {
+				return fmt.Errorf("unsupported health check %q, use tcp://, http:// or https://", req.HealthCheck)
+			}
+		}
+	}
+
 	portals, err := cloud.GetInstances(target)
 	if err != nil {
 		return err
 	}
 
+	dialer := &tls.Dialer{Config: tlsConfig}
 	pool := pond.New(1000, 10000)
 	for _, p := range portals {
 		portal := p
@@ -90,8 +187,11 @@ func action(cmd *cobra.Command, args []string) error {
 			}
 			log := log.WithFields(fields)
 
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
 			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
-			rawconn, err := tls.Dial("tcp", addr, tlsConfig)
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
 			if err != nil {
 				log.Error(err.Error())
 				return
@@ -101,40 +201,33 @@ func action(cmd *cobra.Command, args []string) error {
 			defer conn.Close()
 
 			c := portalpb.NewDRPCPortalClient(conn)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
 
+			var r *portalpb.ServiceResponse
 			switch cmd.Name() {
 			case "restart":
-				r, err := c.ServiceRestart(ctx, &portalpb.ServiceRequest{Name: strings.Join(args, " ")})
-				if err != nil {
-					log.Error(err.Error())
-					return
-				}
-				log.WithField("state", r.GetState()).Info(r.GetMessage())
+				r, err = c.ServiceRestart(ctx, req)
 			case "start":
-				r, err := c.ServiceStart(ctx, &portalpb.ServiceRequest{Name: strings.Join(args, " ")})
-				if err != nil {
-					log.Error(err.Error())
-					return
-				}
-				log.WithField("state", r.GetState()).Info(r.GetMessage())
+				r, err = c.ServiceStart(ctx, req)
 			case "stop":
-				r, err := c.ServiceStop(ctx, &portalpb.ServiceRequest{Name: strings.Join(args, " ")})
-				if err != nil {
-					log.Error(err.Error())
-					return
-				}
-				log.WithField("state", r.GetState()).Info(r.GetMessage())
-			case "status":
-				r, err := c.ServiceStatus(ctx, &portalpb.ServiceRequest{Name: strings.Join(args, " ")})
-				if err != nil {
-					log.Error(err.Error())
-					return
-				}
-				log.WithField("state", r.GetState()).Infof("Loadstate: \"%s\", Activestate: \"%s\", Substate: \"%s\"", r.GetLoadstate(), r.GetActivestate(), r.GetSubstate())
+				r, err = c.ServiceStop(ctx, req)
+			case "enable":
+				r, err = c.ServiceEnable(ctx, req)
+			case "disable":
+				r, err = c.ServiceDisable(ctx, req)
+			case "mask":
+				r, err = c.ServiceMask(ctx, req)
+			case "unmask":
+				r, err = c.ServiceUnmask(ctx, req)
+			case "reload":
+				r, err = c.ServiceReload(ctx, req)
+			case "daemon-reload":
+				r, err = c.ServiceDaemonReload(ctx, &portalpb.ServiceDaemonReloadRequest{})
 			}
-
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", r.GetState()).Info(r.GetMessage())
 		})
 	}
 	pool.StopAndWait()
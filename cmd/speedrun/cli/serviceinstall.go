@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var installCmd = &cobra.Command{
+	Use:     "install <unitfile>",
+	Short:   "Install a unit file to /etc/systemd/system and reload systemd",
+	Example: "  speedrun service install ./app.service --enable --start\n  speedrun service install ./units/backup --name backup.timer --enable",
+	Args:    cobra.ExactArgs(1),
+	RunE:    install,
+}
+
+var overrideCmd = &cobra.Command{
+	Use:     "override <servicename> <dropin>",
+	Short:   "Install the override.conf drop-in of a unit and reload systemd",
+	Example: "  speedrun service override nginx ./nginx-limits.conf --start",
+	Args:    cobra.ExactArgs(2),
+	RunE:    install,
+}
+
+func init() {
+	installCmd.Flags().String("name", "", "Name of the unit, the base name of the unit file by default")
+	for _, c := range []*cobra.Command{installCmd, overrideCmd} {
+		c.Flags().Bool("enable", false, "Enable the unit")
+		c.Flags().Bool("start", false, "Start the unit, or restart it if it runs and its files changed")
+	}
+}
+
+func install(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	req := &portalpb.UnitInstallRequest{}
+	if req.Enable, err = cmd.Flags().GetBool("enable"); err != nil {
+		return err
+	}
+	if req.Start, err = cmd.Flags().GetBool("start"); err != nil {
+		return err
+	}
+
+	local := args[0]
+	if cmd.Name() == "override" {
+		req.Name, local = args[0], args[1]
+	} else {
+		if req.Name, err = cmd.Flags().GetString("name"); err != nil {
+			return err
+		}
+		if req.Name == "" {
+			req.Name = filepath.Base(local)
+		}
+	}
+	if req.Content, err = os.ReadFile(local); err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			var r *portalpb.ServiceResponse
+			if cmd.Name() == "override" {
+				r, err = c.UnitOverride(ctx, req)
+			} else {
+				r, err = c.UnitInstall(ctx, req)
+			}
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", r.GetState()).Info(r.GetMessage())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var serviceListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List units by state and name pattern, hosts with the same units are grouped",
+	Example: "  speedrun service list --state failed\n  speedrun service list --state active --pattern 'app-*'",
+	Args:    cobra.NoArgs,
+	RunE:    serviceList,
+}
+
+func init() {
+	serviceListCmd.Flags().StringSlice("state", nil, "Only list units in this load, active or sub state, repeatable")
+	serviceListCmd.Flags().StringSlice("pattern", nil, "Only list units matching this glob pattern, repeatable")
+}
+
+func serviceList(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	states, err := cmd.Flags().GetStringSlice("state")
+	if err != nil {
+		return err
+	}
+
+	patterns, err := cmd.Flags().GetStringSlice("pattern")
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var results []hostResult
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := c.ServiceList(ctx, &portalpb.ServiceListRequest{States: states, Patterns: patterns})
+			res := hostResult{host: portal.Name, err: err}
+			if err == nil {
+				res.output = formatUnitTable(r.GetUnits())
+			}
+
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		})
+	}
+	pool.StopAndWait()
+
+	printGroupedResults(results)
+	return nil
+}
+
+// formatUnitTable renders units as an aligned table sorted by name.
+func formatUnitTable(units []*portalpb.UnitInfo) string {
+	if len(units) == 0 {
+		return "no matching units"
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].GetName() < units[j].GetName()
+	})
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "UNIT\tLOAD\tACTIVE\tSUB\tDESCRIPTION")
+	for _, u := range units {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", u.GetName(), u.GetLoadstate(), u.GetActivestate(), u.GetSubstate(), u.GetDescription())
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var serviceLogsCmd = &cobra.Command{
+	Use:     "logs <servicename>",
+	Short:   "Print the journal entries of a unit",
+	Example: "  speedrun service logs nginx -f --since 10m\n  speedrun service logs backup.timer --since '2024-01-01 00:00' --until 1h -p err",
+	Args:    cobra.ExactArgs(1),
+	RunE:    serviceLogs,
+}
+
+// journalPriorityNames maps syslog priorities to the names journalctl uses.
+var journalPriorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+func init() {
+	serviceLogsCmd.Flags().Int32P("lines", "n", 10, "Number of most recent entries to print, all entries in range when --since is set and this isn't")
+	serviceLogsCmd.Flags().BoolP("follow", "f", false, "Keep printing entries as they are logged")
+	serviceLogsCmd.Flags().String("since", "", "Only print entries newer than this, a duration like 10m or a journalctl timestamp")
+	serviceLogsCmd.Flags().String("until", "", "Only print entries older than this, a duration like 10m or a journalctl timestamp")
+	serviceLogsCmd.Flags().StringP("priority", "p", "", "Only print entries with this priority or more important, e.g. err or 3")
+}
+
+func serviceLogs(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	req := &portalpb.ServiceLogsRequest{Name: args[0]}
+	if req.Lines, err = cmd.Flags().GetInt32("lines"); err != nil {
+		return err
+	}
+	if req.Follow, err = cmd.Flags().GetBool("follow"); err != nil {
+		return err
+	}
+	if req.Since, err = cmd.Flags().GetString("since"); err != nil {
+		return err
+	}
+	if req.Until, err = cmd.Flags().GetString("until"); err != nil {
+		return err
+	}
+	if req.Priority, err = cmd.Flags().GetString("priority"); err != nil {
+		return err
+	}
+	if req.Since != "" && !cmd.Flags().Changed("lines") {
+		req.Lines = 0
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			// Following lasts until interrupted.
+			var ctx context.Context
+			var cancel context.CancelFunc
+			if req.Follow {
+				ctx, cancel = context.WithCancel(cmd.Context())
+			} else {
+				ctx, cancel = context.WithTimeout(cmd.Context(), time.Second*30)
+			}
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			stream, err := c.ServiceLogs(ctx, req)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			for {
+				r, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Error(err.Error())
+					}
+					return
+				}
+				printJournalEntry(portal.Name, r)
+			}
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// printJournalEntry logs a journal entry as a structured record, its level
+// follows the syslog priority of the entry.
+func printJournalEntry(host string, e *portalpb.ServiceLogsResponse) {
+	priority := "unknown"
+	if p := int(e.GetPriority()); p >= 0 && p < len(journalPriorityNames) {
+		priority = journalPriorityNames[p]
+	}
+
+	entry := log.WithFields(log.Fields{
+		"host":      host,
+		"timestamp": time.UnixMicro(e.GetTimestamp()).Format(time.RFC3339Nano),
+		"priority":  priority,
+		"unit":      e.GetUnit(),
+	})
+	if e.GetIdentifier() != "" {
+		entry = entry.WithField("identifier", e.GetIdentifier())
+	}
+	if e.GetPid() != 0 {
+		entry = entry.WithField("pid", e.GetPid())
+	}
+
+	switch {
+	case e.GetPriority() <= 3:
+		entry.Error(e.GetMessage())
+	case e.GetPriority() == 4:
+		entry.Warn(e.GetMessage())
+	default:
+		entry.Info(e.GetMessage())
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+func init() {
+	statusCmd.Flags().String("sort", "host", "Sort by host, restarts, memory, cpu or since")
+}
+
+type serviceStatusRow struct {
+	host   string
+	status *portalpb.ServiceStatusResponse
+}
+
+func serviceStatus(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	sortBy, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		return err
+	}
+	less, ok := serviceStatusSorts[sortBy]
+	if !ok {
+		return fmt.Errorf("can't sort by %s", sortBy)
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var rows []serviceStatusRow
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := c.ServiceStatus(ctx, &portalpb.ServiceRequest{Name: strings.Join(args, " ")})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			mu.Lock()
+			rows = append(rows, serviceStatusRow{host: portal.Name, status: r})
+			mu.Unlock()
+		})
+	}
+	pool.StopAndWait()
+
+	if len(rows) == 0 {
+		return nil
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if less(rows[i].status, rows[j].status) {
+			return true
+		}
+		if less(rows[j].status, rows[i].status) {
+			return false
+		}
+		return rows[i].host < rows[j].host
+	})
+
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tACTIVE\tUNIT FILE\tPID\tSINCE\tRESTARTS\tMEMORY\tCPU\tDESCRIPTION")
+	for _, r := range rows {
+		s := r.status
+		fmt.Fprintf(w, "%s\t%s/%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			r.host,
+			s.GetActivestate(), s.GetSubstate(),
+			orDash(s.GetUnitFileState()),
+			orDash(pidString(s.GetMainPid())),
+			orDash(sinceString(s.GetActiveSince())),
+			s.GetRestarts(),
+			orDash(bytesString(s.GetMemory())),
+			orDash(cpuString(s.GetCpuNsec())),
+			s.GetDescription(),
+		)
+	}
+	return w.Flush()
+}
+
+// serviceStatusSorts order statuses so that the most interesting ones, e.g.
+// the ones restarting the most, come first.
+var serviceStatusSorts = map[string]func(a, b *portalpb.ServiceStatusResponse) bool{
+	"host":     func(a, b *portalpb.ServiceStatusResponse) bool { return false },
+	"restarts": func(a, b *portalpb.ServiceStatusResponse) bool { return a.GetRestarts() > b.GetRestarts() },
+	"memory":   func(a, b *portalpb.ServiceStatusResponse) bool { return a.GetMemory() > b.GetMemory() },
+	"cpu":      func(a, b *portalpb.ServiceStatusResponse) bool { return a.GetCpuNsec() > b.GetCpuNsec() },
+	"since":    func(a, b *portalpb.ServiceStatusResponse) bool { return a.GetActiveSince() > b.GetActiveSince() },
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func pidString(pid uint32) string {
+	if pid == 0 {
+		return ""
+	}
+	return fmt.Sprint(pid)
+}
+
+func sinceString(usec int64) string {
+	if usec == 0 {
+		return ""
+	}
+	return time.Since(time.UnixMicro(usec)).Round(time.Second).String()
+}
+
+func cpuString(nsec uint64) string {
+	if nsec == 0 {
+		return ""
+	}
+	return time.Duration(nsec).Round(time.Millisecond).String()
+}
+
+// bytesString renders a byte count with a binary unit.
+func bytesString(n uint64) string {
+	if n == 0 {
+		return ""
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var shellCmd = &cobra.Command{
+	Use:     "shell",
+	Short:   "Run commands interactively on remote servers",
+	Example: "  speedrun shell\n  speedrun shell --target \"Labels.role == 'nginx'\"",
+	Args:    cobra.NoArgs,
+	RunE:    shell,
+}
+
+const shellHelp = `Every line is run as a command on all selected hosts. Built-in commands:
+  :hosts            list the selected hosts
+  :narrow <target>  keep only the selected hosts that match the target expression
+  :widen <target>   add the hosts of the fleet that match the target expression
+  :reset            go back to the hosts selected by --target
+  :history          list previous commands, !! repeats the last one and !<n> the n-th
+  :help             show this help
+  :exit             leave the shell, Ctrl-D works too
+Ctrl-C cancels the command that is currently running.`
+
+const maxHistory = 1000
+
+func init() {
+	shellCmd.SetUsageTemplate(usage)
+	shellCmd.Flags().Duration("timeout", 10*time.Second, "Time to wait for each command to finish before cancelling it")
+}
+
+type fleetShell struct {
+	fleet    *fleet
+	all      []cloud.Instance
+	initial  []cloud.Instance
+	selected []cloud.Instance
+	timeout  time.Duration
+	sigs     chan os.Signal
+
+	history     []string
+	historyFile string
+}
+
+func shell(cmd *cobra.Command, _ []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	all, err := cloud.GetInstances("")
+	if err != nil {
+		return err
+	}
+
+	initial, err := cloud.Filter(all, target)
+	if err != nil {
+		return err
+	}
+	if len(initial) == 0 {
+		return fmt.Errorf("no instances found")
+	}
+
+	sh := &fleetShell{
+		fleet:    newFleet(tlsConfig, usePrivateIP),
+		all:      all,
+		initial:  initial,
+		selected: initial,
+		timeout:  timeout,
+		sigs:     make(chan os.Signal, 1),
+	}
+	defer sh.fleet.Close()
+
+	home, err := homedir.Dir()
+	if err == nil {
+		sh.historyFile = filepath.Join(home, ".speedrun", "shell_history")
+		sh.loadHistory()
+	}
+
+	// The shell takes care of signals itself so that Ctrl-C only cancels the
+	// running command instead of the whole session.
+	signal.Notify(sh.sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sh.sigs)
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	log.Infof("Selected %d of %d hosts, type :help for help", len(sh.selected), len(sh.all))
+	for {
+		fmt.Printf("speedrun [%d hosts]> ", len(sh.selected))
+
+		var line string
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				fmt.Println()
+				return nil
+			}
+			line = strings.TrimSpace(l)
+		case sig := <-sh.sigs:
+			fmt.Println()
+			if sig == syscall.SIGTERM {
+				return nil
+			}
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+		if exit := sh.handle(line); exit {
+			return nil
+		}
+	}
+}
+
+// handle runs a single line of input and reports whether the shell should
+// exit.
+func (sh *fleetShell) handle(line string) bool {
+	if strings.HasPrefix(line, "!") {
+		expanded, err := sh.expandHistory(line)
+		if err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		fmt.Println(expanded)
+		line = expanded
+	}
+	sh.addHistory(line)
+
+	if line == "exit" {
+		return true
+	}
+	if strings.HasPrefix(line, ":") {
+		return sh.builtin(line)
+	}
+
+	args, err := splitArgs(line)
+	if err != nil {
+		log.Error(err.Error())
+		return false
+	}
+	return sh.exec(args)
+}
+
+func (sh *fleetShell) builtin(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":exit", ":quit":
+		return true
+	case ":help":
+		fmt.Println(shellHelp)
+	case ":hosts":
+		for _, i := range sh.selected {
+			fmt.Printf("%s\t%s\n", i.Name, i.GetAddress(sh.fleet.usePrivateIP))
+		}
+	case ":history":
+		for n, l := range sh.history {
+			fmt.Printf("%5d  %s\n", n+1, l)
+		}
+	case ":reset":
+		sh.selected = sh.initial
+	case ":narrow":
+		subset, err := cloud.Filter(sh.selected, arg)
+		if err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		if len(subset) == 0 {
+			log.Warn("No selected hosts match, keeping the current selection")
+			return false
+		}
+		sh.selected = subset
+	case ":widen":
+		subset, err := cloud.Filter(sh.all, arg)
+		if err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		sh.selected = mergeInstances(sh.selected, subset)
+	default:
+		log.Errorf("Unknown command %s, type :help for help", name)
+	}
+	return false
+}
+
+// exec runs the command on all selected hosts and prints the output grouped
+// by hosts that returned the same result. It reports whether a SIGTERM was
+// received in the meantime.
+func (sh *fleetShell) exec(args []string) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan []hostResult, 1)
+	go func() {
+		done <- sh.run(ctx, args)
+	}()
+
+	exit := false
+	for {
+		select {
+		case results := <-done:
+			printGroupedResults(results)
+			return exit
+		case sig := <-sh.sigs:
+			log.Warn("Cancelling command")
+			exit = exit || sig == syscall.SIGTERM
+			cancel()
+		}
+	}
+}
+
+func (sh *fleetShell) run(ctx context.Context, args []string) []hostResult {
+	var mu sync.Mutex
+	results := make([]hostResult, 0, len(sh.selected))
+
+	pool := pond.New(1000, 10000)
+	for _, p := range sh.selected {
+		portal := p
+		pool.Submit(func() {
+			ctx, cancel := context.WithTimeout(ctx, sh.timeout)
+			defer cancel()
+
+			res := hostResult{host: portal.Name}
+			c, err := sh.fleet.client(ctx, portal)
+			if err == nil {
+				var r *portalpb.CommandResponse
+				r, err = c.RunCommand(ctx, &portalpb.CommandRequest{Name: args[0], Args: args[1:]})
+				res.output = r.GetMessage()
+			}
+			res.err = err
+
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+		})
+	}
+	pool.StopAndWait()
+	return results
+}
+
+// mergeInstances returns the union of both lists, keeping the order of a.
+func mergeInstances(a, b []cloud.Instance) []cloud.Instance {
+	seen := map[string]bool{}
+	merged := make([]cloud.Instance, 0, len(a)+len(b))
+	for _, list := range [][]cloud.Instance{a, b} {
+		for _, i := range list {
+			if seen[i.Name] {
+				continue
+			}
+			seen[i.Name] = true
+			merged = append(merged, i)
+		}
+	}
+	return merged
+}
+
+func (sh *fleetShell) loadHistory() {
+	data, err := os.ReadFile(sh.historyFile)
+	if err != nil {
+		return
+	}
+	for _, l := range strings.Split(string(data), "\n") {
+		if l != "" {
+			sh.history = append(sh.history, l)
+		}
+	}
+	if len(sh.history) > maxHistory {
+		sh.history = sh.history[len(sh.history)-maxHistory:]
+	}
+}
+
+func (sh *fleetShell) addHistory(line string) {
+	sh.history = append(sh.history, line)
+	if len(sh.history) > maxHistory {
+		sh.history = sh.history[1:]
+	}
+
+	if sh.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(sh.historyFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		log.Debugf("Couldn't save history: %v", err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+func (sh *fleetShell) expandHistory(line string) (string, error) {
+	if len(sh.history) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+	if line == "!!" {
+		return sh.history[len(sh.history)-1], nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(line, "!"))
+	if err != nil || n < 1 || n > len(sh.history) {
+		return "", fmt.Errorf("%s: event not found", line)
+	}
+	return sh.history[n-1], nil
+}
+
+// splitArgs splits a command line into arguments, honouring single and
+// double quotes as well as backslash escapes.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var statCmd = &cobra.Command{
+	Use:     "stat <path>",
+	Short:   "Show size, mode, owner, modification time and checksum of a file",
+	Example: "  speedrun file stat /etc/nginx/nginx.conf",
+	Args:    cobra.ExactArgs(1),
+	RunE:    stat,
+}
+
+var checksumCmd = &cobra.Command{
+	Use:     "checksum <path>",
+	Short:   "Group hosts by the SHA-256 of a file to spot drift",
+	Example: "  speedrun file checksum /etc/nginx/nginx.conf\n  speedrun file checksum /etc/nginx/nginx.conf --expect 9f86d08...",
+	Args:    cobra.ExactArgs(1),
+	RunE:    stat,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{statCmd, checksumCmd} {
+		c.Flags().String("expect", "", "Only show hosts where the SHA-256 of the file differs from this one")
+	}
+}
+
+func stat(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	expect, err := cmd.Flags().GetString("expect")
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var results []hostResult
+	matching := 0
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*30)
+			defer cancel()
+
+			r, err := func() (*portalpb.FileStatResponse, error) {
+				addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+				rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+				if err != nil {
+					return nil, err
+				}
+
+				conn := drpcconn.New(rawconn)
+				defer conn.Close()
+
+				c := portalpb.NewDRPCPortalClient(conn)
+				r, err := c.FileStat(ctx, &portalpb.FileStatRequest{Path: args[0]})
+				if err == nil && !r.GetExists() {
+					err = fmt.Errorf("%s does not exist", r.GetPath())
+				}
+				return r, err
+			}()
+
+			if err == nil && expect != "" && r.GetSha256() == expect {
+				mu.Lock()
+				matching++
+				mu.Unlock()
+				return
+			}
+
+			if cmd.Name() == "checksum" {
+				res := hostResult{host: portal.Name, output: r.GetSha256(), err: err}
+				if err == nil && r.GetDir() {
+					res.output = "(directory)"
+				}
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+				return
+			}
+
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("size", r.GetSize()).
+				WithField("mode", fmt.Sprintf("%04o", r.GetMode())).
+				WithField("owner", r.GetOwner()).
+				WithField("group", r.GetGroup()).
+				WithField("mtime", time.Unix(r.GetMtime(), 0).Format(time.RFC3339)).
+				WithField("sha256", r.GetSha256()).
+				Info(r.GetPath())
+		})
+	}
+	pool.StopAndWait()
+
+	if cmd.Name() == "checksum" {
+		printGroupedResults(results)
+	}
+	if expect != "" {
+		log.Infof("%d of %d hosts have the expected checksum", matching, len(portals))
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <localdir> <remotedir>",
+	Short: "Make a remote directory match a local one",
+	Long: `Make a remote directory match a local one. The portal reports the checksums
+of the files it has so only new and changed files are sent. Directories are
+created as needed to hold the files, symlinks are skipped.`,
+	Example: "  speedrun file sync ./site /var/www/site --delete",
+	Args:    cobra.ExactArgs(2),
+	RunE:    syncDir,
+}
+
+func init() {
+	syncCmd.Flags().Bool("delete", false, "Remove remote files that don't exist locally")
+	syncCmd.Flags().Bool("dry-run", false, "Only report what would change")
+	syncCmd.Flags().String("owner", "", "Owner of the remote files, name or uid")
+	syncCmd.Flags().String("group", "", "Group of the remote files, name or gid")
+	syncCmd.Flags().Duration("timeout", 10*time.Minute, "Time to wait for each host to finish before cancelling")
+}
+
+// syncStats counts what happened to the files of a host.
+type syncStats struct {
+	created, updated, removed, unchanged, failed int
+}
+
+func syncDir(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	del, err := cmd.Flags().GetBool("delete")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	owner, err := cmd.Flags().GetString("owner")
+	if err != nil {
+		return err
+	}
+
+	group, err := cmd.Flags().GetString("group")
+	if err != nil {
+		return err
+	}
+
+	localDir, remoteDir := args[0], args[1]
+	local, err := localManifest(localDir)
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Syncing %s to %s", localDir, remoteDir)
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			entries, err := remoteManifest(ctx, c, remoteDir)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			remote := map[string]*portalpb.ManifestEntry{}
+			for _, e := range entries {
+				remote[e.GetPath()] = e
+			}
+
+			var stats syncStats
+			for _, l := range local {
+				if l.GetDir() {
+					continue
+				}
+				name := path.Join(remoteDir, l.GetPath())
+				r, ok := remote[l.GetPath()]
+				if ok && r.GetSha256() == l.GetSha256() && r.GetMode() == l.GetMode() && owner == "" && group == "" {
+					stats.unchanged++
+					continue
+				}
+				if dryRun {
+					if ok {
+						log.Infof("Would update %s", name)
+						stats.updated++
+					} else {
+						log.Infof("Would create %s", name)
+						stats.created++
+					}
+					continue
+				}
+
+				header := &portalpb.FileWriteRequest{
+					Path:    name,
+					Mode:    l.GetMode(),
+					Owner:   owner,
+					Group:   group,
+					Parents: true,
+					Sha256:  l.GetSha256(),
+					Size:    l.GetSize(),
+				}
+				localPath := filepath.Join(localDir, filepath.FromSlash(l.GetPath()))
+				w, err := pushFile(ctx, c, header, func() (io.ReadCloser, error) {
+					return os.Open(localPath)
+				})
+				switch {
+				case err != nil:
+					log.WithField("name", name).Error(err.Error())
+					stats.failed++
+				case w.GetState() == portalpb.State_UNCHANGED:
+					stats.unchanged++
+				case ok:
+					stats.updated++
+				default:
+					stats.created++
+				}
+			}
+
+			if del {
+				removed, failed := syncRemove(ctx, c, log, remoteDir, local, entries, dryRun)
+				stats.removed += removed
+				stats.failed += failed
+			}
+
+			state := portalpb.State_UNCHANGED
+			if stats.created+stats.updated+stats.removed > 0 {
+				state = portalpb.State_CHANGED
+			}
+			log = log.WithField("state", state).
+				WithField("created", stats.created).
+				WithField("updated", stats.updated).
+				WithField("removed", stats.removed).
+				WithField("unchanged", stats.unchanged)
+			if stats.failed > 0 {
+				log.WithField("failed", stats.failed).Error("Sync incomplete")
+				return
+			}
+			if dryRun {
+				log.Info("Dry run, nothing changed")
+				return
+			}
+			log.Info("Synced")
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// syncRemove removes the remote entries that don't exist locally, deepest
+// first so that directories are empty by the time they are removed. It
+// returns how many entries were removed and how many couldn't be.
+func syncRemove(ctx context.Context, c portalpb.DRPCPortalClient, log *log.Entry, remoteDir string, local, remote []*portalpb.ManifestEntry, dryRun bool) (int, int) {
+	keep := map[string]bool{}
+	for _, l := range local {
+		keep[l.GetPath()] = true
+	}
+
+	var extra []string
+	for _, r := range remote {
+		if !keep[r.GetPath()] {
+			extra = append(extra, r.GetPath())
+		}
+	}
+	sort.Slice(extra, func(a, b int) bool {
+		da, db := strings.Count(extra[a], "/"), strings.Count(extra[b], "/")
+		if da != db {
+			return da > db
+		}
+		return extra[a] > extra[b]
+	})
+
+	removed, failed := 0, 0
+	for _, p := range extra {
+		name := path.Join(remoteDir, p)
+		if dryRun {
+			log.Infof("Would remove %s", name)
+			removed++
+			continue
+		}
+		if _, err := c.FileRemove(ctx, &portalpb.FileRemoveRequest{Path: name}); err != nil {
+			log.WithField("name", name).Error(err.Error())
+			failed++
+			continue
+		}
+		removed++
+	}
+	return removed, failed
+}
+
+// remoteManifest collects the entries the portal streams for dir.
+func remoteManifest(ctx context.Context, c portalpb.DRPCPortalClient, dir string) ([]*portalpb.ManifestEntry, error) {
+	stream, err := c.FileManifest(ctx, &portalpb.FileManifestRequest{Path: dir})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var entries []*portalpb.ManifestEntry
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, m.GetEntries()...)
+	}
+}
+
+// localManifest lists the regular files and directories below dir the same
+// way the portal does for FileManifest.
+func localManifest(dir string) ([]*portalpb.ManifestEntry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var entries []*portalpb.ManifestEntry
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			log.Warnf("Skipping %s, not a regular file", p)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		entry := &portalpb.ManifestEntry{
+			Path: filepath.ToSlash(rel),
+			Size: info.Size(),
+			Mode: uint32(info.Mode().Perm()),
+			Dir:  d.IsDir(),
+		}
+		if !d.IsDir() {
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			h := sha256.New()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			entry.Sha256 = hex.EncodeToString(h.Sum(nil))
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
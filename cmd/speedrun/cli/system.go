@@ -61,6 +61,7 @@ func systemAction(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	dialer := &tls.Dialer{Config: tlsConfig}
 	pool := pond.New(1000, 10000)
 	for _, p := range portals {
 		portal := p
@@ -71,8 +72,11 @@ func systemAction(cmd *cobra.Command, _ []string) error {
 			}
 			log := log.WithFields(fields)
 
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
 			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
-			rawconn, err := tls.Dial("tcp", addr, tlsConfig)
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
 			if err != nil {
 				log.Error(err.Error())
 				return
@@ -82,8 +86,6 @@ func systemAction(cmd *cobra.Command, _ []string) error {
 			defer conn.Close()
 
 			c := portalpb.NewDRPCPortalClient(conn)
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-			defer cancel()
 
 			switch cmd.Name() {
 			case "reboot":
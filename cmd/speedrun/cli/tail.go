@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var tailCmd = &cobra.Command{
+	Use:     "tail <path>",
+	Short:   "Print the last lines of a file",
+	Example: "  speedrun file tail /var/log/syslog -n 20\n  speedrun file tail -f /var/log/nginx/error.log --grep 'upstream timed out'",
+	Args:    cobra.ExactArgs(1),
+	RunE:    tail,
+}
+
+func init() {
+	tailCmd.Flags().Int32P("lines", "n", 10, "Number of lines to print")
+	tailCmd.Flags().BoolP("follow", "f", false, "Keep printing lines as they are appended")
+	tailCmd.Flags().String("grep", "", "Only print lines matching this regular expression, filtered on the portal")
+}
+
+func tail(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	lines, err := cmd.Flags().GetInt32("lines")
+	if err != nil {
+		return err
+	}
+
+	follow, err := cmd.Flags().GetBool("follow")
+	if err != nil {
+		return err
+	}
+
+	pattern, err := cmd.Flags().GetString("grep")
+	if err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			// Following lasts until interrupted.
+			var ctx context.Context
+			var cancel context.CancelFunc
+			if follow {
+				ctx, cancel = context.WithCancel(cmd.Context())
+			} else {
+				ctx, cancel = context.WithTimeout(cmd.Context(), time.Second*10)
+			}
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			stream, err := c.FileTail(ctx, &portalpb.FileTailRequest{Path: args[0], Lines: lines, Follow: follow, Pattern: pattern})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			w := newHostWriter(portal.Name)
+			for {
+				r, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Error(err.Error())
+					}
+					return
+				}
+				for _, line := range r.GetLines() {
+					w.printLine([]byte(line))
+				}
+			}
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
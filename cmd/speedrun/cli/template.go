@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+	"storj.io/drpc/drpcconn"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template <template> <remote>",
+	Short: "Render a template for every host and write it where it differs",
+	Long: `Render a Go text/template for every host and write the result to <remote> on
+hosts where it differs from the current content. The template gets the
+instance fields, e.g. {{ .Name }}, {{ .PrivateAddress }} or {{ .Labels.zone }},
+and the values from --vars files under {{ .Vars }}, with keys lower-cased.`,
+	Example: "  speedrun file template ./haproxy.cfg.tmpl /etc/haproxy/haproxy.cfg --vars prod.toml\n  speedrun file template ./motd.tmpl /etc/motd --dry-run",
+	Args:    cobra.ExactArgs(2),
+	RunE:    renderTemplate,
+}
+
+func init() {
+	templateCmd.Flags().StringArray("vars", []string{}, "File with variables for the template (toml, yaml or json), can be repeated")
+	templateCmd.Flags().Bool("dry-run", false, "Only show the differences, don't write anything")
+	addWriteFlags(templateCmd, "Octal file mode, defaults to the mode of the remote file or 0644 for new ones")
+	templateCmd.Flags().Duration("timeout", time.Minute, "Time to wait for each host before cancelling")
+}
+
+// templateData is what templates are executed against.
+type templateData struct {
+	cloud.Instance
+	Vars map[string]interface{}
+}
+
+func renderTemplate(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	varsFiles, err := cmd.Flags().GetStringArray("vars")
+	if err != nil {
+		return err
+	}
+	vars, err := loadVars(varsFiles)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(filepath.Base(args[0])).Option("missingkey=error").ParseFiles(args[0])
+	if err != nil {
+		return err
+	}
+
+	base := &portalpb.FileWriteRequest{Path: args[1]}
+	if err := applyWriteFlags(cmd, base); err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, templateData{Instance: portal, Vars: vars}); err != nil {
+				log.Error(err.Error())
+				return
+			}
+			content := buf.Bytes()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			st, err := c.FileStat(ctx, &portalpb.FileStatRequest{Path: args[1]})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			var current []byte
+			truncated := false
+			if st.GetExists() {
+				r, err := c.FileRead(ctx, &portalpb.FileReadRequest{Path: args[1]})
+				if err != nil {
+					log.Error(err.Error())
+					return
+				}
+				truncated = r.GetTruncated()
+				if truncated {
+					log.Warn("Remote file is too large to compare")
+				}
+				current = r.GetContent()
+			}
+
+			same := st.GetExists() && !truncated && bytes.Equal(current, content)
+			if !same && !truncated {
+				printDiff(portal.Name, args[1], current, content)
+			}
+			if dryRun {
+				state := portalpb.State_CHANGED
+				if same {
+					state = portalpb.State_UNCHANGED
+				}
+				log.WithField("state", state).Info("Dry run, not writing")
+				return
+			}
+
+			sum := sha256.Sum256(content)
+			header := proto.Clone(base).(*portalpb.FileWriteRequest)
+			header.Sha256 = hex.EncodeToString(sum[:])
+			header.Size = int64(len(content))
+			w, err := pushFile(ctx, c, header, func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(content)), nil
+			})
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+			log.WithField("state", w.GetState()).Info(w.GetMessage())
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+// loadVars merges the variables of all files, later files win.
+func loadVars(files []string) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, f := range files {
+		v := viper.New()
+		v.SetConfigFile(f)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		for k, val := range v.AllSettings() {
+			vars[k] = val
+		}
+	}
+	return vars, nil
+}
+
+// printDiff prints a unified diff between the remote and the rendered
+// content of a host in one go.
+func printDiff(host, path string, current, rendered []byte) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(current),
+		B:        splitLines(rendered),
+		FromFile: host + ":" + path,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+	if err != nil || diff == "" {
+		return
+	}
+
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	fmt.Fprint(os.Stdout, diff)
+	if !strings.HasSuffix(diff, "\n") {
+		fmt.Fprintln(os.Stdout)
+	}
+}
+
+// splitLines splits data into lines keeping their newlines, which is what
+// difflib expects.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var topCmd = &cobra.Command{
+	Use:     "top",
+	Short:   "Show a live table of load, CPU, memory and swap usage across the fleet",
+	Example: "  speedrun top --target \"Labels.role == 'nginx'\"\n  speedrun top --sort mem --interval 5s\n  speedrun top --once --sort load",
+	Args:    cobra.NoArgs,
+	RunE:    top,
+}
+
+func init() {
+	topCmd.Flags().DurationP("interval", "i", 2*time.Second, "How often to refresh the stats")
+	topCmd.Flags().String("sort", "cpu", "Sort by cpu, load, mem, swap, uptime or host")
+	topCmd.Flags().Bool("once", false, "Print the stats once and exit")
+}
+
+type topRow struct {
+	host  string
+	stats *portalpb.CPUusageResponse
+	err   error
+}
+
+func top(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return err
+	}
+	if interval < time.Second {
+		return fmt.Errorf("interval must be at least 1s")
+	}
+
+	sortBy, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		return err
+	}
+	less, ok := topSorts[sortBy]
+	if !ok {
+		return fmt.Errorf("can't sort by %s", sortBy)
+	}
+
+	once, err := cmd.Flags().GetBool("once")
+	if err != nil {
+		return err
+	}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	f := newFleet(tlsConfig, usePrivateIP)
+	defer f.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rows := pollStats(cmd.Context(), f, portals, interval)
+		if cmd.Context().Err() != nil {
+			return nil
+		}
+		sortTopRows(rows, less)
+		if !once {
+			// Clear the screen and move the cursor home.
+			fmt.Print("\033[H\033[2J")
+		}
+		printTop(rows, sortBy)
+		if once {
+			return nil
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollStats fetches the stats of every portal in parallel, reusing the
+// connections of the fleet between polls.
+func pollStats(ctx context.Context, f *fleet, portals []cloud.Instance, timeout time.Duration) []topRow {
+	var mu sync.Mutex
+	rows := make([]topRow, 0, len(portals))
+
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			row := topRow{host: portal.Name}
+			c, err := f.client(ctx, portal)
+			if err == nil {
+				row.stats, err = c.CPUusage(ctx, &portalpb.CPUusageRequest{})
+			}
+			row.err = err
+
+			mu.Lock()
+			rows = append(rows, row)
+			mu.Unlock()
+		})
+	}
+	pool.StopAndWait()
+	return rows
+}
+
+// topSorts order hosts so that the busiest come first.
+var topSorts = map[string]func(a, b *portalpb.CPUusageResponse) bool{
+	"host":   func(a, b *portalpb.CPUusageResponse) bool { return false },
+	"cpu":    func(a, b *portalpb.CPUusageResponse) bool { return a.GetCpuPercent() > b.GetCpuPercent() },
+	"load":   func(a, b *portalpb.CPUusageResponse) bool { return a.GetLoadavg1() > b.GetLoadavg1() },
+	"mem":    func(a, b *portalpb.CPUusageResponse) bool { return memPercent(a) > memPercent(b) },
+	"swap":   func(a, b *portalpb.CPUusageResponse) bool { return swapPercent(a) > swapPercent(b) },
+	"uptime": func(a, b *portalpb.CPUusageResponse) bool { return a.GetUptime() < b.GetUptime() },
+}
+
+// sortTopRows sorts the rows with less, ties and hosts that failed to
+// respond go by host name, the failed ones last.
+func sortTopRows(rows []topRow, less func(a, b *portalpb.CPUusageResponse) bool) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if (a.err != nil) != (b.err != nil) {
+			return b.err != nil
+		}
+		if a.err == nil {
+			if less(a.stats, b.stats) {
+				return true
+			}
+			if less(b.stats, a.stats) {
+				return false
+			}
+		}
+		return a.host < b.host
+	})
+}
+
+func printTop(rows []topRow, sortBy string) {
+	failed := 0
+	for _, r := range rows {
+		if r.err != nil {
+			failed++
+		}
+	}
+
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	fmt.Printf("%s, %d hosts, %d unreachable, sorted by %s\n\n", time.Now().Format("15:04:05"), len(rows), failed, sortBy)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tLOAD1\tLOAD5\tLOAD15\tCPUS\tCPU%\tMEM%\tMEM\tSWAP%\tUPTIME")
+	for _, r := range rows {
+		if r.err != nil {
+			continue
+		}
+		s := r.stats
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\t%.1f\t%.1f\t%s/%s\t%.1f\t%s\n",
+			r.host,
+			s.GetLoadavg1(), s.GetLoadavg5(), s.GetLoadavg15(),
+			s.GetCpus(),
+			s.GetCpuPercent(),
+			memPercent(s),
+			orDash(bytesString(s.GetMemTotal()-s.GetMemAvailable())), orDash(bytesString(s.GetMemTotal())),
+			swapPercent(s),
+			uptimeString(s.GetUptime()),
+		)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		fmt.Println()
+		for _, r := range rows {
+			if r.err != nil {
+				fmt.Printf("%s: %s\n", r.host, r.err)
+			}
+		}
+	}
+}
+
+// uptimeString renders seconds of uptime like uptime(1) does, e.g. 12 days, 3:04.
+func uptimeString(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	days := int(d.Hours()) / 24
+	hm := fmt.Sprintf("%d:%02d", int(d.Hours())%24, int(d.Minutes())%60)
+	switch days {
+	case 0:
+		return hm
+	case 1:
+		return "1 day, " + hm
+	}
+	return fmt.Sprintf("%d days, %s", days, hm)
+}
+
+func memPercent(s *portalpb.CPUusageResponse) float64 {
+	if s.GetMemTotal() == 0 {
+		return 0
+	}
+	return float64(s.GetMemTotal()-s.GetMemAvailable()) / float64(s.GetMemTotal()) * 100
+}
+
+func swapPercent(s *portalpb.CPUusageResponse) float64 {
+	if s.GetSwapTotal() == 0 {
+		return 0
+	}
+	return float64(s.GetSwapTotal()-s.GetSwapFree()) / float64(s.GetSwapTotal()) * 100
+}
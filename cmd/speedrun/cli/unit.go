@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/pkg/speedrun/cloud"
+	portalpb "github.com/dpogorzelski/speedrun/proto/portal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"storj.io/drpc/drpcconn"
+)
+
+var unitCmd = &cobra.Command{
+	Use:   "unit",
+	Short: "Inspect systemd timers, sockets and mounts",
+	Long: `Inspect systemd timers, sockets and mounts. To start, stop or enable them use
+the service commands with the full unit name, e.g. speedrun service start backup.timer`,
+	TraverseChildren: true,
+}
+
+var timersCmd = &cobra.Command{
+	Use:     "timers [pattern]",
+	Short:   "List timers with their next and last elapse",
+	Example: "  speedrun unit timers\n  speedrun unit timers 'logrotate*'",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    listUnits,
+}
+
+var socketsCmd = &cobra.Command{
+	Use:     "sockets [pattern]",
+	Short:   "List sockets with their listen addresses and connections",
+	Example: "  speedrun unit sockets",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    listUnits,
+}
+
+var mountsCmd = &cobra.Command{
+	Use:     "mounts [pattern]",
+	Short:   "List mounts with their source and file system type",
+	Example: "  speedrun unit mounts 'mnt-*'",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    listUnits,
+}
+
+func init() {
+	unitCmd.SetUsageTemplate(usage)
+	unitCmd.AddCommand(timersCmd)
+	unitCmd.AddCommand(socketsCmd)
+	unitCmd.AddCommand(mountsCmd)
+}
+
+func listUnits(cmd *cobra.Command, args []string) error {
+	usePrivateIP := viper.GetBool("portal.use-private-ip")
+
+	tlsConfig, err := cloud.SetupTLS()
+	if err != nil {
+		return err
+	}
+
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return err
+	}
+
+	req := &portalpb.ServiceListRequest{Type: strings.TrimSuffix(cmd.Name(), "s"), Patterns: args}
+
+	portals, err := cloud.GetInstances(target)
+	if err != nil {
+		return err
+	}
+
+	dialer := &tls.Dialer{Config: tlsConfig}
+	pool := pond.New(1000, 10000)
+	for _, p := range portals {
+		portal := p
+		pool.Submit(func() {
+			fields := log.Fields{
+				"host":    portal.Name,
+				"address": portal.GetAddress(usePrivateIP),
+			}
+			log := log.WithFields(fields)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Second*10)
+			defer cancel()
+
+			addr := net.JoinHostPort(portal.GetAddress(usePrivateIP), "1337")
+			rawconn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			conn := drpcconn.New(rawconn)
+			defer conn.Close()
+
+			c := portalpb.NewDRPCPortalClient(conn)
+
+			r, err := c.ServiceList(ctx, req)
+			if err != nil {
+				log.Error(err.Error())
+				return
+			}
+
+			units := r.GetUnits()
+			sort.Slice(units, func(i, j int) bool {
+				ti, tj := units[i].GetTimer().GetNextElapse(), units[j].GetTimer().GetNextElapse()
+				if (ti == 0) != (tj == 0) {
+					return tj == 0
+				}
+				if ti != tj {
+					return ti < tj
+				}
+				return units[i].GetName() < units[j].GetName()
+			})
+
+			w := newHostWriter(portal.Name)
+			if len(units) == 0 {
+				w.printLine([]byte("no units found"))
+			}
+			for _, u := range units {
+				w.printLine([]byte(formatUnit(u)))
+			}
+		})
+	}
+	pool.StopAndWait()
+	return nil
+}
+
+func formatUnit(u *portalpb.UnitInfo) string {
+	line := fmt.Sprintf("%s %s/%s", u.GetName(), u.GetActivestate(), u.GetSubstate())
+	switch {
+	case u.GetLoadstate() != "loaded":
+		return fmt.Sprintf("%s %s", u.GetName(), u.GetLoadstate())
+	case u.GetTimer() != nil:
+		t := u.GetTimer()
+		return fmt.Sprintf("%s next %s, last %s, activates %s", line, formatUsec(t.GetNextElapse()), formatUsec(t.GetLastTrigger()), t.GetUnit())
+	case u.GetSocket() != nil:
+		s := u.GetSocket()
+		return fmt.Sprintf("%s listening on %s, %d accepted, %d connected", line, strings.Join(s.GetListen(), ", "), s.GetAccepted(), s.GetConnections())
+	case u.GetMount() != nil:
+		m := u.GetMount()
+		return fmt.Sprintf("%s %s on %s type %s", line, m.GetWhat(), m.GetWhere(), m.GetType())
+	}
+	return line
+}
+
+// formatUsec renders microseconds since the epoch along with how far away
+// they are from now.
+func formatUsec(usec int64) string {
+	if usec == 0 {
+		return "n/a"
+	}
+	t := time.UnixMicro(usec)
+	d := time.Until(t).Round(time.Second)
+	if d < 0 {
+		return fmt.Sprintf("%s (%s ago)", t.Format("2006-01-02 15:04:05 MST"), -d)
+	}
+	return fmt.Sprintf("%s (in %s)", t.Format("2006-01-02 15:04:05 MST"), d)
+}
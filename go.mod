@@ -51,6 +51,7 @@ require (
 	github.com/antonmedv/expr v1.9.0
 	github.com/coreos/go-systemd/v22 v22.3.2
 	github.com/mitchellh/go-homedir v1.1.0
+	github.com/pmezard/go-difflib v1.0.0
 	google.golang.org/protobuf v1.28.0
 	storj.io/drpc v0.0.30
 )
@@ -0,0 +1,334 @@
+package portal
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"storj.io/drpc"
+	"storj.io/drpc/drpcctx"
+)
+
+// AuditRecord describes a single RPC handled by the portal.
+type AuditRecord struct {
+	Time       string          `json:"time"`
+	Identity   string          `json:"identity"`
+	Remote     string          `json:"remote"`
+	Method     string          `json:"method"`
+	Args       json.RawMessage `json:"args,omitempty"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs float64         `json:"duration_ms"`
+	Prev       string          `json:"prev"`
+}
+
+// auditEntry is a line of the audit log. Hash is the SHA-256 of the raw
+// record bytes, and since every record carries the hash of the one before
+// it, changing or removing a line breaks the chain from there on.
+type auditEntry struct {
+	Record json.RawMessage `json:"record"`
+	Hash   string          `json:"hash"`
+}
+
+// AuditLog appends hash chained records of every RPC to a file, rotating it
+// once it grows past maxSize. Rotated files keep the chain going.
+type AuditLog struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	prev string
+}
+
+// OpenAuditLog opens the audit log at path for appending and picks up the
+// chain where the last record left it.
+func OpenAuditLog(path string, maxSize int64) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	a := &AuditLog{path: path, maxSize: maxSize}
+
+	files, err := AuditFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(files) - 1; i >= 0 && a.prev == ""; i-- {
+		a.prev, err = lastAuditHash(files[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Close closes the underlying file.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Close()
+}
+
+// Write chains the record to the previous one and appends it to the log.
+func (a *AuditLog) Write(r AuditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r.Prev = a.prev
+	record, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(record)
+	hash := hex.EncodeToString(sum[:])
+
+	line, err := json.Marshal(auditEntry{Record: record, Hash: hash})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if a.maxSize > 0 && a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.f.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if err := a.f.Sync(); err != nil {
+		return err
+	}
+
+	a.prev = hash
+	return nil
+}
+
+func (a *AuditLog) open() error {
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	a.f = f
+	a.size = info.Size()
+	return nil
+}
+
+func (a *AuditLog) rotate() error {
+	if err := a.f.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%s", a.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(a.path, rotated); err != nil {
+		return err
+	}
+	return a.open()
+}
+
+// Handler wraps a drpc handler so that every RPC it handles gets recorded.
+func (a *AuditLog) Handler(handler drpc.Handler) drpc.Handler {
+	return auditHandler{audit: a, handler: handler}
+}
+
+type auditHandler struct {
+	audit   *AuditLog
+	handler drpc.Handler
+}
+
+func (h auditHandler) HandleRPC(stream drpc.Stream, rpc string) error {
+	start := time.Now()
+	s := &auditStream{Stream: stream}
+	err := h.handler.HandleRPC(s, rpc)
+
+	ctx := stream.Context()
+	record := AuditRecord{
+		Time:       start.UTC().Format(time.RFC3339Nano),
+		Identity:   "anonymous",
+		Remote:     remoteAddr(ctx),
+		Method:     path.Base(rpc),
+		Args:       s.args,
+		Outcome:    "ok",
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if id, idErr := PeerIdentity(ctx); idErr == nil {
+		record.Identity = id.String()
+	}
+	if err != nil {
+		record.Outcome = "error"
+		if errors.Is(err, errPermissionDenied) {
+			record.Outcome = "denied"
+		}
+		record.Error = err.Error()
+	}
+
+	if auditErr := h.audit.Write(record); auditErr != nil {
+		log.WithField("context", "audit").Errorf("Couldn't write audit record for %s: %v", record.Method, auditErr)
+	}
+	return err
+}
+
+// auditStream remembers the first message received on a stream, which holds
+// the arguments of the call.
+type auditStream struct {
+	drpc.Stream
+	args json.RawMessage
+}
+
+func (s *auditStream) MsgRecv(msg drpc.Message, enc drpc.Encoding) error {
+	err := s.Stream.MsgRecv(msg, enc)
+	if err != nil || s.args != nil {
+		return err
+	}
+
+	if m, ok := msg.(proto.Message); ok {
+		if data, err := protojson.Marshal(redactBytes(m)); err == nil {
+			s.args = data
+		}
+	}
+	return nil
+}
+
+// redactBytes returns a copy of msg without its bytes fields, they carry file
+// and script contents that have no place in the audit log.
+func redactBytes(msg proto.Message) proto.Message {
+	clone := proto.Clone(msg)
+	m := clone.ProtoReflect()
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.BytesKind {
+			m.Clear(fd)
+		}
+		return true
+	})
+	return clone
+}
+
+func remoteAddr(ctx context.Context) string {
+	tr, ok := drpcctx.Transport(ctx)
+	if !ok {
+		return ""
+	}
+	conn, ok := tr.(interface{ RemoteAddr() net.Addr })
+	if !ok {
+		return ""
+	}
+	return conn.RemoteAddr().String()
+}
+
+// AuditFiles returns the rotated audit logs followed by the current one,
+// oldest first.
+func AuditFiles(path string) ([]string, error) {
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(rotated)
+
+	files := rotated
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// VerifyAudit checks the hash chain across the given files, oldest first. The
+// first record is trusted as the anchor of the chain. It returns the number
+// of verified records.
+func VerifyAudit(files []string) (int, error) {
+	count := 0
+	prev := ""
+	first := true
+
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return count, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			var entry auditEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				f.Close()
+				return count, fmt.Errorf("%s:%d: malformed entry: %v", name, line, err)
+			}
+			var record AuditRecord
+			if err := json.Unmarshal(entry.Record, &record); err != nil {
+				f.Close()
+				return count, fmt.Errorf("%s:%d: malformed record: %v", name, line, err)
+			}
+
+			sum := sha256.Sum256(entry.Record)
+			if hex.EncodeToString(sum[:]) != entry.Hash {
+				f.Close()
+				return count, fmt.Errorf("%s:%d: record does not match its hash", name, line)
+			}
+			if !first && record.Prev != prev {
+				f.Close()
+				return count, fmt.Errorf("%s:%d: chain broken, previous record hash %s expected, got %s", name, line, prev, record.Prev)
+			}
+
+			first = false
+			prev = entry.Hash
+			count++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return count, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return count, nil
+}
+
+func lastAuditHash(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	last := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return "", fmt.Errorf("%s: malformed entry: %v", name, err)
+		}
+		last = entry.Hash
+	}
+	return last, scanner.Err()
+}
@@ -0,0 +1,242 @@
+package portal
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"storj.io/drpc/drpcctx"
+)
+
+// AuthzConfig maps client certificate identities to roles and roles to the
+// RPCs and resources they may use.
+type AuthzConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Roles    []Role        `mapstructure:"roles"`
+	Bindings []RoleBinding `mapstructure:"bindings"`
+}
+
+// Role lists the RPCs a client may call as glob patterns, e.g. "Service*".
+// Services, Paths and Commands further restrict the units, file path
+// prefixes and binaries the RPCs may act upon, empty means unrestricted.
+type Role struct {
+	Name     string   `mapstructure:"name"`
+	RPCs     []string `mapstructure:"rpcs"`
+	Services []string `mapstructure:"services"`
+	Paths    []string `mapstructure:"paths"`
+	Commands []string `mapstructure:"commands"`
+}
+
+// RoleBinding grants a role to clients whose certificate matches any of the
+// common name, organizational unit or subject alternative name patterns.
+type RoleBinding struct {
+	Role string   `mapstructure:"role"`
+	CN   []string `mapstructure:"cn"`
+	OU   []string `mapstructure:"ou"`
+	SAN  []string `mapstructure:"san"`
+}
+
+// Identity describes a client as presented by its verified certificate.
+type Identity struct {
+	CommonName string
+	OUs        []string
+	SANs       []string
+}
+
+func (i Identity) String() string {
+	if i.CommonName == "" && len(i.OUs) == 0 && len(i.SANs) == 0 {
+		return "anonymous"
+	}
+
+	parts := []string{"CN=" + i.CommonName}
+	for _, ou := range i.OUs {
+		parts = append(parts, "OU="+ou)
+	}
+	for _, san := range i.SANs {
+		parts = append(parts, "SAN="+san)
+	}
+	return strings.Join(parts, ",")
+}
+
+// PeerIdentity returns the identity of the client behind a drpc request.
+func PeerIdentity(ctx context.Context) (Identity, error) {
+	tr, ok := drpcctx.Transport(ctx)
+	if !ok {
+		return Identity{}, fmt.Errorf("no transport associated with the request")
+	}
+	conn, ok := tr.(interface{ ConnectionState() tls.ConnectionState })
+	if !ok {
+		return Identity{}, fmt.Errorf("connection is not using TLS")
+	}
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return Identity{}, fmt.Errorf("client did not present a certificate")
+	}
+
+	cert := certs[0]
+	id := Identity{
+		CommonName: cert.Subject.CommonName,
+		OUs:        cert.Subject.OrganizationalUnit,
+	}
+	id.SANs = append(id.SANs, cert.DNSNames...)
+	id.SANs = append(id.SANs, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		id.SANs = append(id.SANs, uri.String())
+	}
+	return id, nil
+}
+
+// Authorizer decides which RPCs a client may call based on its identity.
+type Authorizer struct {
+	roles    map[string]Role
+	bindings []RoleBinding
+}
+
+// NewAuthorizer validates the config and builds an Authorizer from it.
+func NewAuthorizer(config AuthzConfig) (*Authorizer, error) {
+	a := &Authorizer{
+		roles:    map[string]Role{},
+		bindings: config.Bindings,
+	}
+
+	for _, r := range config.Roles {
+		if r.Name == "" {
+			return nil, fmt.Errorf("role without a name")
+		}
+		patterns := append(append([]string{}, r.RPCs...), r.Services...)
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in role %s: %v", p, r.Name, err)
+			}
+		}
+		a.roles[r.Name] = r
+	}
+
+	for _, b := range config.Bindings {
+		if _, ok := a.roles[b.Role]; !ok {
+			return nil, fmt.Errorf("binding refers to unknown role %s", b.Role)
+		}
+	}
+
+	return a, nil
+}
+
+// Roles returns the roles bound to the identity.
+func (a *Authorizer) Roles(id Identity) []Role {
+	var roles []Role
+	for _, b := range a.bindings {
+		if b.matches(id) {
+			roles = append(roles, a.roles[b.Role])
+		}
+	}
+	return roles
+}
+
+// Authorize returns a permission denied error unless one of the roles of the
+// identity allows calling rpc on all of the given resources. Whether the
+// resources are services, paths or commands depends on the RPC.
+func (a *Authorizer) Authorize(id Identity, rpc string, resources ...string) error {
+	for _, r := range a.Roles(id) {
+		if r.allows(rpc, resources) {
+			return nil
+		}
+	}
+	if len(resources) > 0 {
+		return fmt.Errorf("%w: %s may not call %s on %s", errPermissionDenied, id, rpc, strings.Join(resources, ", "))
+	}
+	return fmt.Errorf("%w: %s may not call %s", errPermissionDenied, id, rpc)
+}
+
+// authorize checks whether the client behind ctx may call rpc, a nil
+// Authorizer lets everyone in.
+func (s *Server) authorize(ctx context.Context, rpc string, resources ...string) error {
+	if s.Authz == nil {
+		return nil
+	}
+
+	id, err := PeerIdentity(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermissionDenied, err)
+	}
+	return s.Authz.Authorize(id, rpc, resources...)
+}
+
+func (b RoleBinding) matches(id Identity) bool {
+	if matchAny(b.CN, id.CommonName) {
+		return true
+	}
+	for _, ou := range id.OUs {
+		if matchAny(b.OU, ou) {
+			return true
+		}
+	}
+	for _, san := range id.SANs {
+		if matchAny(b.SAN, san) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) allows(rpc string, resources []string) bool {
+	if !matchAny(r.RPCs, rpc) {
+		return false
+	}
+
+	for _, res := range resources {
+		switch {
+		case strings.HasPrefix(rpc, "Service") || strings.HasPrefix(rpc, "Unit"):
+			unit := unitName(res)
+			if len(r.Services) > 0 && !matchAny(r.Services, res) && !matchAny(r.Services, unit) && !matchAny(r.Services, strings.TrimSuffix(unit, ".service")) {
+				return false
+			}
+		case strings.HasPrefix(rpc, "File"):
+			if len(r.Paths) > 0 && !withinAny(r.Paths, res) {
+				return false
+			}
+		case rpc == "RunCommand" || rpc == "RunScript" || strings.HasPrefix(rpc, "Job"):
+			if len(r.Commands) > 0 && !commandAllowed(r.Commands, res) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchAny(patterns []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withinAny reports whether p equals or lives under one of the prefixes.
+func withinAny(prefixes []string, p string) bool {
+	p = filepath.Clean(p)
+	for _, prefix := range prefixes {
+		prefix = filepath.Clean(prefix)
+		if p == prefix || prefix == "/" || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func commandAllowed(commands []string, name string) bool {
+	binary := resolveBinary(name)
+	for _, c := range commands {
+		if resolveBinary(c) == binary {
+			return true
+		}
+	}
+	return false
+}
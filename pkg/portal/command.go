@@ -1,6 +1,7 @@
 package portal
 
 import (
+	"bytes"
 	"context"
 	"os/exec"
 	"strings"
@@ -17,12 +18,24 @@ func (s *Server) RunCommand(ctx context.Context, in *portal.CommandRequest) (*po
 	log := log.WithFields(fields)
 
 	log.Debugf("Received command: %s %s", in.GetName(), in.GetArgs())
+	if err := s.authorize(ctx, "RunCommand", in.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.Policy.Check(in.GetName(), in.GetArgs()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	var output bytes.Buffer
 	cmd := exec.Command(in.GetName(), in.GetArgs()...)
-	stdout, err := cmd.CombinedOutput()
+	cmd.Stdout = &output
+	cmd.Stderr = &output
 
+	err := runProcess(ctx, cmd, s.GracePeriod)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	return &portal.CommandResponse{Message: strings.TrimSpace(string(stdout))}, nil
+	return &portal.CommandResponse{Message: strings.TrimSpace(output.String())}, nil
 }
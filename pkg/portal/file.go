@@ -1,13 +1,21 @@
 package portal
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os"
+	"unicode/utf8"
 
 	"github.com/apex/log"
 	"github.com/dpogorzelski/speedrun/proto/portal"
 )
 
+// binarySniffLen is how much of a file is looked at to tell binary content
+// from text.
+const binarySniffLen = 8000
+
 func (s *Server) FileRead(ctx context.Context, file *portal.FileReadRequest) (*portal.FileReadResponse, error) {
 	fields := log.Fields{
 		"context": "file",
@@ -17,11 +25,80 @@ func (s *Server) FileRead(ctx context.Context, file *portal.FileReadRequest) (*p
 	log := log.WithFields(fields)
 	log.Debug("Received file read request")
 
-	content, err := os.ReadFile(file.GetPath())
+	path, err := s.Paths.Resolve(file.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileRead", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if info.IsDir() {
+		err := fmt.Errorf("%s is a directory", path)
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	limit := s.MaxFileSize
+	if limit <= 0 || limit > maxMessageSize {
+		limit = maxMessageSize
+	}
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
 
-	return &portal.FileReadResponse{State: portal.State_UNKNOWN, Content: string(content)}, nil
+	size := info.Size()
+	truncated := false
+	if int64(len(content)) > limit {
+		content = content[:limit]
+		truncated = true
+		log.Debugf("File is larger than %d bytes, truncating", limit)
+	}
+	if size < int64(len(content)) {
+		// Files under /proc and friends report a size of 0.
+		size = int64(len(content))
+	}
+
+	return &portal.FileReadResponse{
+		State:     portal.State_UNKNOWN,
+		Content:   content,
+		Truncated: truncated,
+		Binary:    isBinary(content),
+		Size:      size,
+	}, nil
+}
+
+// isBinary reports whether data looks like something other than UTF-8 text.
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	// Don't hold a rune cut in half at the end against the data.
+	for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+		if utf8.Valid(data) {
+			return false
+		}
+		data = data[:len(data)-1]
+	}
+	return !utf8.Valid(data)
 }
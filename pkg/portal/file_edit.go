@@ -0,0 +1,309 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// defaultBlockMarker surrounds managed blocks, {mark} is replaced with BEGIN
+// and END.
+const defaultBlockMarker = "# {mark} SPEEDRUN MANAGED BLOCK"
+
+// FileEnsureLine makes sure a line is present in a file, or absent from it.
+// With a regexp the last matching line gets replaced, otherwise the line is
+// inserted after the last line matching insert_after, before the first line
+// matching insert_before or at the end of the file.
+func (s *Server) FileEnsureLine(ctx context.Context, in *portal.FileEnsureLineRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "ensure-line",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received ensure line request")
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileEnsureLine", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	patterns, err := compilePatterns(in.GetRegexp(), in.GetInsertAfter(), in.GetInsertBefore())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if strings.Contains(in.GetLine(), "\n") {
+		err := fmt.Errorf("line must not contain newlines")
+		log.Error(err.Error())
+		return nil, err
+	}
+	if in.GetAbsent() && in.GetLine() == "" && patterns[0] == nil {
+		err := fmt.Errorf("line or regexp required")
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	changed, msg, err := editLines(path, in.GetCreate(), in.GetAbsent(), in.GetBackup(), func(lines []string) ([]string, string, bool, error) {
+		out, msg, changed := ensureLine(lines, in.GetLine(), patterns[0], patterns[1], patterns[2], in.GetAbsent())
+		return out, msg, changed, nil
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if !changed {
+		return &portal.FileEditResponse{State: portal.State_UNCHANGED, Message: msg}, nil
+	}
+	return &portal.FileEditResponse{State: portal.State_CHANGED, Message: msg}, nil
+}
+
+// FileEnsureBlock makes sure a block of lines surrounded by marker lines is
+// present in a file with the given content, or absent from it. New blocks are
+// placed like FileEnsureLine places lines.
+func (s *Server) FileEnsureBlock(ctx context.Context, in *portal.FileEnsureBlockRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "ensure-block",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received ensure block request")
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileEnsureBlock", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	patterns, err := compilePatterns(in.GetInsertAfter(), in.GetInsertBefore())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	marker := in.GetMarker()
+	if marker == "" {
+		marker = defaultBlockMarker
+	}
+	if !strings.Contains(marker, "{mark}") {
+		err := fmt.Errorf("marker must contain {mark}")
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	block := splitFileLines(in.GetBlock())
+	changed, msg, err := editLines(path, in.GetCreate(), in.GetAbsent(), in.GetBackup(), func(lines []string) ([]string, string, bool, error) {
+		return ensureBlock(lines, block, marker, patterns[0], patterns[1], in.GetAbsent())
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if !changed {
+		return &portal.FileEditResponse{State: portal.State_UNCHANGED, Message: msg}, nil
+	}
+	return &portal.FileEditResponse{State: portal.State_CHANGED, Message: msg}, nil
+}
+
+// editLines applies edit to the lines of the file at path and writes the
+// result back atomically if anything changed, keeping mode and ownership. A
+// missing file is left alone when removing content and created empty first
+// when create is set.
+func editLines(path string, create, absent, backup bool, edit func([]string) ([]string, string, bool, error)) (bool, string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if absent {
+			return false, "File does not exist", nil
+		}
+		if create {
+			err = nil
+		}
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	lines, msg, changed, err := edit(splitFileLines(string(data)))
+	if err != nil || !changed {
+		return false, msg, err
+	}
+
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		content += "\n"
+	}
+	opts := writeOptions{uid: -1, gid: -1, backup: backup}
+	if _, err := writeAtomic(path, strings.NewReader(content), opts); err != nil {
+		return false, "", err
+	}
+	return true, msg, nil
+}
+
+func ensureLine(lines []string, line string, match, after, before *regexp.Regexp, absent bool) ([]string, string, bool) {
+	matches := func(l string) bool {
+		if match != nil {
+			return match.MatchString(l)
+		}
+		return l == line
+	}
+
+	if absent {
+		kept := make([]string, 0, len(lines))
+		for _, l := range lines {
+			if !matches(l) {
+				kept = append(kept, l)
+			}
+		}
+		if len(kept) == len(lines) {
+			return lines, "Line not present", false
+		}
+		return kept, fmt.Sprintf("Removed %d lines", len(lines)-len(kept)), true
+	}
+
+	last := -1
+	for i, l := range lines {
+		if matches(l) {
+			last = i
+		}
+	}
+	if last >= 0 {
+		if lines[last] == line {
+			return lines, "Line already present", false
+		}
+		out := append([]string{}, lines...)
+		out[last] = line
+		return out, fmt.Sprintf("Replaced line %d", last+1), true
+	}
+	for _, l := range lines {
+		if l == line {
+			return lines, "Line already present", false
+		}
+	}
+
+	pos := insertPosition(lines, after, before)
+	return insertLines(lines, pos, line), fmt.Sprintf("Inserted line %d", pos+1), true
+}
+
+// ensureBlock refuses to touch a file whose markers don't pair up, adding
+// another block could leave it with two.
+func ensureBlock(lines, block []string, marker string, after, before *regexp.Regexp, absent bool) ([]string, string, bool, error) {
+	begin := strings.Replace(marker, "{mark}", "BEGIN", 1)
+	end := strings.Replace(marker, "{mark}", "END", 1)
+	want := append(append([]string{begin}, block...), end)
+
+	start, stop := -1, -1
+scan:
+	for i, l := range lines {
+		switch {
+		case l == begin && start < 0:
+			start = i
+		case l == end && start < 0:
+			return nil, "", false, fmt.Errorf("end marker on line %d has no begin marker", i+1)
+		case l == end:
+			stop = i
+			break scan
+		}
+	}
+	if start >= 0 && stop < 0 {
+		return nil, "", false, fmt.Errorf("begin marker on line %d has no end marker", start+1)
+	}
+
+	if start >= 0 {
+		out := append([]string{}, lines[:start]...)
+		if absent {
+			out = append(out, lines[stop+1:]...)
+			return out, "Removed block", true, nil
+		}
+		if equalLines(lines[start:stop+1], want) {
+			return lines, "Block already present", false, nil
+		}
+		out = append(out, want...)
+		out = append(out, lines[stop+1:]...)
+		return out, "Updated block", true, nil
+	}
+	if absent {
+		return lines, "Block not present", false, nil
+	}
+
+	pos := insertPosition(lines, after, before)
+	return insertLines(lines, pos, want...), fmt.Sprintf("Inserted block at line %d", pos+1), true, nil
+}
+
+// insertPosition returns the index new lines go to: after the last line
+// matching after, before the first line matching before, or the end.
+func insertPosition(lines []string, after, before *regexp.Regexp) int {
+	if after != nil {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if after.MatchString(lines[i]) {
+				return i + 1
+			}
+		}
+	}
+	if before != nil {
+		for i, l := range lines {
+			if before.MatchString(l) {
+				return i
+			}
+		}
+	}
+	return len(lines)
+}
+
+func insertLines(lines []string, pos int, add ...string) []string {
+	out := make([]string, 0, len(lines)+len(add))
+	out = append(out, lines[:pos]...)
+	out = append(out, add...)
+	return append(out, lines[pos:]...)
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitFileLines splits file content into lines without their newlines.
+func splitFileLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// compilePatterns compiles the given regular expressions, empty ones result
+// in nil.
+func compilePatterns(exprs ...string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, len(exprs))
+	for i, e := range exprs {
+		if e == "" {
+			continue
+		}
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = re
+	}
+	return patterns, nil
+}
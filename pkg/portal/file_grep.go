@@ -0,0 +1,172 @@
+package portal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// maxGrepMatches caps the matches returned by a single FileGrep call.
+const maxGrepMatches = 10000
+
+// FileGrep searches the files matching a glob for a regular expression and
+// returns the matching lines along with the requested lines of context.
+// Directories and binary files are skipped. The response is cut short once it
+// reaches maxGrepMatches matches or maxMessageSize bytes.
+func (s *Server) FileGrep(ctx context.Context, in *portal.FileGrepRequest) (*portal.FileGrepResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "grep",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received file grep request: %s", in.GetPattern())
+
+	paths, err := s.Paths.Glob(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileGrep", paths...); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	expr := in.GetPattern()
+	if in.GetIgnoreCase() {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	max := int(in.GetMaxMatches())
+	if max <= 0 || max > maxGrepMatches {
+		max = maxGrepMatches
+	}
+
+	g := &grep{pattern: pattern, context: int(in.GetContext()), max: max}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if g.truncated {
+			break
+		}
+		if err := g.file(path); err != nil {
+			log.WithField("name", path).Debugf("Skipping: %s", err)
+		}
+	}
+
+	return &portal.FileGrepResponse{
+		Lines:     g.lines,
+		Matches:   int32(g.matches),
+		Truncated: g.truncated,
+	}, nil
+}
+
+var errBinaryFile = errors.New("binary file")
+
+type grep struct {
+	pattern *regexp.Regexp
+	context int
+	max     int
+
+	lines     []*portal.GrepLine
+	size      int
+	matches   int
+	truncated bool
+}
+
+// add appends lines to the response unless that would make it too large, in
+// which case the response is marked as truncated.
+func (g *grep) add(lines ...*portal.GrepLine) bool {
+	size := 0
+	for _, l := range lines {
+		// Leave some room for the encoding of the other fields.
+		size += len(l.Path) + len(l.Text) + 16
+	}
+	if g.size+size > maxMessageSize {
+		g.truncated = true
+		return false
+	}
+	g.size += size
+	g.lines = append(g.lines, lines...)
+	return true
+}
+
+// file searches a single file, lines before a match are kept in a ring of
+// the context size and lines after it are counted down.
+func (g *grep) file(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return errors.New("not a regular file")
+	}
+
+	r := bufio.NewReaderSize(f, fileChunkSize)
+	if head, _ := r.Peek(binarySniffLen); isBinary(head) {
+		return errBinaryFile
+	}
+
+	var before []*portal.GrepLine
+	after := 0
+	var number int64
+	for {
+		text, err := r.ReadString('\n')
+		if text == "" && err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		number++
+		text = strings.TrimRight(text, "\r\n")
+		if len(text) > maxLineLen {
+			text = text[:maxLineLen]
+		}
+		line := &portal.GrepLine{Path: path, Number: number, Text: text}
+
+		switch {
+		case g.pattern.MatchString(line.Text):
+			if g.matches >= g.max {
+				g.truncated = true
+				return nil
+			}
+			line.Match = true
+			if !g.add(append(before, line)...) {
+				return nil
+			}
+			before = before[:0]
+			after = g.context
+			g.matches++
+		case after > 0:
+			if !g.add(line) {
+				return nil
+			}
+			after--
+		case g.context > 0:
+			if len(before) == g.context {
+				before = before[1:]
+			}
+			before = append(before, line)
+		}
+	}
+}
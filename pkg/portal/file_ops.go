@@ -0,0 +1,279 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const defaultDirMode = 0755
+
+func (s *Server) FileChmod(ctx context.Context, in *portal.FileChmodRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "chmod",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received chmod request: %04o", in.GetMode())
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileChmod", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := guardPath(path, in.GetRecursive(), in.GetForce()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if in.GetMode() == 0 || in.GetMode() > 0777 {
+		err := fmt.Errorf("invalid mode %04o, only permission bits are supported", in.GetMode())
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	opts := writeOptions{mode: os.FileMode(in.GetMode()), uid: -1, gid: -1}
+	n, err := s.applyTree(path, in.GetRecursive(), opts)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return changedEntries(n, fmt.Sprintf("Mode already %04o", in.GetMode()), fmt.Sprintf("Changed mode of %d entries to %04o", n, in.GetMode())), nil
+}
+
+func (s *Server) FileChown(ctx context.Context, in *portal.FileChownRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "chown",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received chown request: %s:%s", in.GetOwner(), in.GetGroup())
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileChown", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := guardPath(path, in.GetRecursive(), in.GetForce()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if in.GetOwner() == "" && in.GetGroup() == "" {
+		err := fmt.Errorf("owner or group required")
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	uid, gid, err := lookupOwner(in.GetOwner(), in.GetGroup())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	n, err := s.applyTree(path, in.GetRecursive(), writeOptions{uid: uid, gid: gid})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return changedEntries(n, "Ownership already matches", fmt.Sprintf("Changed ownership of %d entries", n)), nil
+}
+
+func (s *Server) FileMkdir(ctx context.Context, in *portal.FileMkdirRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "mkdir",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received mkdir request")
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileMkdir", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if in.GetMode() > 0777 {
+		err := fmt.Errorf("invalid mode %04o, only permission bits are supported", in.GetMode())
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	uid, gid, err := lookupOwner(in.GetOwner(), in.GetGroup())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	opts := writeOptions{mode: os.FileMode(in.GetMode()), uid: uid, gid: gid}
+
+	info, err := os.Stat(path)
+	if err == nil {
+		if !info.IsDir() {
+			err := fmt.Errorf("%s exists and is not a directory", path)
+			log.Error(err.Error())
+			return nil, err
+		}
+		changed, err := applyMetadata(path, opts)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		if changed {
+			return &portal.FileEditResponse{State: portal.State_CHANGED, Message: "Directory exists, metadata updated"}, nil
+		}
+		return &portal.FileEditResponse{State: portal.State_UNCHANGED, Message: "Directory already exists"}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if opts.mode == 0 {
+		opts.mode = defaultDirMode
+	}
+	if in.GetParents() {
+		err = os.MkdirAll(path, defaultDirMode)
+	} else {
+		err = os.Mkdir(path, opts.mode)
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	// The umask has a say in the mode Mkdir ends up with.
+	if _, err := applyMetadata(path, opts); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.FileEditResponse{State: portal.State_CHANGED, Message: "Created " + path}, nil
+}
+
+// FileMove renames a file or directory. A missing source with an existing
+// destination counts as already moved.
+func (s *Server) FileMove(ctx context.Context, in *portal.FileMoveRequest) (*portal.FileEditResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "move",
+		"name":    in.GetSource(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received move request to %s", in.GetDestination())
+
+	src, err := s.Paths.ResolveLink(in.GetSource())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	dst, err := s.Paths.ResolveLink(in.GetDestination())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileMove", src, dst); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := guardPath(src, false, false); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	_, srcErr := os.Lstat(src)
+	_, dstErr := os.Lstat(dst)
+	switch {
+	case errors.Is(srcErr, os.ErrNotExist) && dstErr == nil:
+		return &portal.FileEditResponse{State: portal.State_UNCHANGED, Message: "Already moved"}, nil
+	case srcErr != nil:
+		log.Error(srcErr.Error())
+		return nil, srcErr
+	case dstErr == nil && !in.GetOverwrite():
+		err := fmt.Errorf("%s already exists", dst)
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.FileEditResponse{State: portal.State_CHANGED, Message: fmt.Sprintf("Moved %s to %s", src, dst)}, nil
+}
+
+// guardPath refuses operations on / and recursive ones on top-level
+// directories such as /etc or /usr. Recursive operations elsewhere have to be
+// forced.
+func guardPath(path string, recursive, force bool) error {
+	if path == "/" {
+		return fmt.Errorf("%w: refusing to operate on /", errPermissionDenied)
+	}
+	if recursive && !force {
+		return fmt.Errorf("%w: recursive operation on %s requires force", errPermissionDenied, path)
+	}
+	if recursive && filepath.Dir(path) == "/" {
+		return fmt.Errorf("%w: refusing to operate recursively on %s", errPermissionDenied, path)
+	}
+	return nil
+}
+
+// applyTree applies opts to path, and everything below it when recursive
+// is set, and returns the number of entries that changed. Entries the path
+// policy protects are left alone and symlinks only get their ownership
+// changed, changing their mode would change their target.
+func (s *Server) applyTree(path string, recursive bool, opts writeOptions) (int, error) {
+	count := 0
+	apply := func(p string, symlink bool) error {
+		o := opts
+		if symlink {
+			o.mode = 0
+		}
+		changed, err := applyMetadata(p, o)
+		if changed {
+			count++
+		}
+		return err
+	}
+
+	if !recursive {
+		return count, apply(path, false)
+	}
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := s.Paths.check(p); err != nil {
+			log.WithField("name", p).Debugf("Skipping: %s", err)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return apply(p, d.Type()&fs.ModeSymlink != 0)
+	})
+	return count, err
+}
+
+func changedEntries(n int, unchanged, changed string) *portal.FileEditResponse {
+	if n == 0 {
+		return &portal.FileEditResponse{State: portal.State_UNCHANGED, Message: unchanged}
+	}
+	return &portal.FileEditResponse{State: portal.State_CHANGED, Message: changed}
+}
@@ -0,0 +1,190 @@
+package portal
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// fileChunkSize is the amount of file content sent per streamed message.
+const fileChunkSize = 64 * 1024
+
+// FilePull streams a file to the client. Directories and glob patterns are
+// sent as a tar archive with names relative to the parent directory of each
+// match. Single files can be resumed by passing the offset the client already
+// has along with the SHA-256 of those bytes, archives are always sent whole.
+func (s *Server) FilePull(in *portal.FilePullRequest, stream portal.DRPCPortal_FilePullStream) error {
+	fields := log.Fields{
+		"context": "file",
+		"command": "pull",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received file pull request")
+
+	paths, err := s.Paths.Glob(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+	if err := s.authorize(stream.Context(), "FilePull", paths...); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	if !isGlob(in.GetPath()) {
+		info, err := os.Stat(paths[0])
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if !info.IsDir() {
+			err := s.pullFile(stream, paths[0], info, in.GetOffset(), in.GetPrefixSha256())
+			if err != nil {
+				log.Error(err.Error())
+			}
+			return err
+		}
+	}
+
+	if err := s.pullArchive(stream, paths); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *Server) pullFile(stream portal.DRPCPortal_FilePullStream, path string, info fs.FileInfo, offset int64, prefix string) error {
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The file may have been rotated or rewritten since the client got its
+	// partial copy, only resume if the bytes it has are still the same.
+	if offset < 0 || offset > info.Size() || prefix == "" {
+		offset = 0
+	}
+	if offset > 0 {
+		h := sha256.New()
+		if _, err := io.CopyN(h, f, offset); err != nil {
+			return err
+		}
+		if hex.EncodeToString(h.Sum(nil)) != prefix {
+			log.WithField("name", path).Debug("Partial copy of the client differs, starting over")
+			offset = 0
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = stream.Send(&portal.FilePullResponse{
+		Name:   filepath.Base(path),
+		Size:   info.Size(),
+		Mode:   uint32(info.Mode().Perm()),
+		Offset: offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Only send what the header announced so the client can tell a complete
+	// download from one cut short, even if the file keeps growing.
+	_, err = io.CopyBuffer(filePullWriter{stream}, io.LimitReader(f, info.Size()-offset), make([]byte, fileChunkSize))
+	return err
+}
+
+func (s *Server) pullArchive(stream portal.DRPCPortal_FilePullStream, paths []string) error {
+	err := stream.Send(&portal.FilePullResponse{Name: filepath.Base(paths[0]), Archive: true})
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriterSize(filePullWriter{stream}, fileChunkSize)
+	tw := tar.NewWriter(w)
+	for _, root := range paths {
+		base := filepath.Dir(root)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				return nil
+			}
+			// Directories may hold files that must not leave the host.
+			if _, err := s.Paths.Resolve(path); err != nil {
+				log.WithField("name", path).Debugf("Skipping: %s", err)
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			// Log files keep growing while we read them, only send what
+			// the header announced.
+			_, err = io.CopyN(tw, f, hdr.Size)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// filePullWriter sends everything written to it as FilePull messages.
+type filePullWriter struct {
+	stream portal.DRPCPortal_FilePullStream
+}
+
+func (w filePullWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := w.stream.Send(&portal.FilePullResponse{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
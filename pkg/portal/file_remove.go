@@ -0,0 +1,78 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// FileRemove removes a file or an empty directory, or a whole tree when
+// recursive and force are set. Removing something that isn't there is not an error.
+func (s *Server) FileRemove(ctx context.Context, in *portal.FileRemoveRequest) (*portal.FileRemoveResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "remove",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received file remove request")
+
+	path, err := s.Paths.ResolveLink(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileRemove", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := guardPath(path, in.GetRecursive(), in.GetForce()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &portal.FileRemoveResponse{State: portal.State_UNCHANGED, Message: "File does not exist"}, nil
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if !in.GetRecursive() || !info.IsDir() {
+		if err := os.Remove(path); err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		return &portal.FileRemoveResponse{State: portal.State_CHANGED, Message: "Removed " + path}, nil
+	}
+
+	// Refuse to take files the policy protects down with the tree.
+	count := 0
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := s.Paths.check(p); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := os.RemoveAll(path); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.FileRemoveResponse{State: portal.State_CHANGED, Message: fmt.Sprintf("Removed %s and %d entries below it", path, count-1)}, nil
+}
@@ -0,0 +1,79 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// FileStat returns the metadata and checksum of a file, a file that doesn't
+// exist is reported as such rather than as an error.
+func (s *Server) FileStat(ctx context.Context, in *portal.FileStatRequest) (*portal.FileStatResponse, error) {
+	fields := log.Fields{
+		"context": "file",
+		"command": "stat",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received file stat request")
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "FileStat", path); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &portal.FileStatResponse{Path: path}, nil
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	r := &portal.FileStatResponse{
+		Path:   path,
+		Size:   info.Size(),
+		Mode:   uint32(info.Mode().Perm()),
+		Mtime:  info.ModTime().Unix(),
+		Dir:    info.IsDir(),
+		Exists: true,
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		r.Owner, r.Group = ownerNames(st.Uid, st.Gid)
+	}
+	if info.Mode().IsRegular() {
+		r.Sha256, err = fileSHA256(path)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ownerNames returns the user and group names for uid and gid, falling back
+// to the numeric ids for unknown ones.
+func ownerNames(uid, gid uint32) (string, string) {
+	owner := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
@@ -0,0 +1,112 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// FileManifest lists the regular files and directories below a directory
+// along with the SHA-256 of every file, so clients can tell which files need
+// to be sent. The entries are streamed in batches of about fileChunkSize
+// bytes. A missing directory yields a single message without entries.
+func (s *Server) FileManifest(in *portal.FileManifestRequest, stream portal.DRPCPortal_FileManifestStream) error {
+	ctx := stream.Context()
+	fields := log.Fields{
+		"context": "file",
+		"command": "manifest",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received file manifest request")
+
+	root, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+	if err := s.authorize(ctx, "FileManifest", root); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	info, err := os.Stat(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return stream.Send(&portal.FileManifestResponse{})
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if !info.IsDir() {
+		err := fmt.Errorf("%s is not a directory", root)
+		log.Error(err.Error())
+		return err
+	}
+
+	var entries []*portal.ManifestEntry
+	size := 0
+	flush := func() error {
+		err := stream.Send(&portal.FileManifestResponse{Entries: entries, Exists: true})
+		entries, size = nil, 0
+		return err
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if path == root || (!d.IsDir() && !d.Type().IsRegular()) {
+			return nil
+		}
+		if _, err := s.Paths.Resolve(path); err != nil {
+			log.WithField("name", path).Debugf("Skipping: %s", err)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry := &portal.ManifestEntry{
+			Path: filepath.ToSlash(rel),
+			Size: info.Size(),
+			Mode: uint32(info.Mode().Perm()),
+			Dir:  d.IsDir(),
+		}
+		if !d.IsDir() {
+			entry.Sha256, err = fileSHA256(path)
+			if err != nil {
+				return err
+			}
+		}
+		entries = append(entries, entry)
+		size += len(entry.Path) + len(entry.Sha256) + 32
+		if size >= fileChunkSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package portal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const (
+	// maxLineLen caps the length of the lines sent, longer ones are cut. It
+	// is also how much of a line without a newline is held back while
+	// following a file.
+	maxLineLen = 1024 * 1024
+
+	// tailReadSize caps how much of the file is read for the last lines and
+	// per poll while following, the rest is picked up by the next poll.
+	tailReadSize = 16 * 1024 * 1024
+)
+
+// FileTail streams the last lines of a file and, when following, whatever gets
+// appended to it afterwards. Rotated files are reopened and truncated ones
+// read again from the start. A pattern limits the lines sent to those
+// matching it, the line count applies before filtering like tail | grep.
+func (s *Server) FileTail(in *portal.FileTailRequest, stream portal.DRPCPortal_FileTailStream) error {
+	fields := log.Fields{
+		"context": "file",
+		"command": "tail",
+		"name":    in.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received file tail request")
+
+	path, err := s.Paths.Resolve(in.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+	if err := s.authorize(stream.Context(), "FileTail", path); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	var pattern *regexp.Regexp
+	if in.GetPattern() != "" {
+		pattern, err = regexp.Compile(in.GetPattern())
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	t, err := newTailer(path, int(in.GetLines()))
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer t.Close()
+
+	send := func(lines []string) error {
+		if pattern != nil {
+			matching := lines[:0]
+			for _, l := range lines {
+				if pattern.MatchString(l) {
+					matching = append(matching, l)
+				}
+			}
+			lines = matching
+		}
+		// Keep every message well below the packet limit of drpc.
+		for len(lines) > 0 {
+			n, size := 0, 0
+			for n < len(lines) && (n == 0 || size+len(lines[n]) <= maxMessageSize) {
+				size += len(lines[n])
+				n++
+			}
+			if err := stream.Send(&portal.FileTailResponse{Lines: lines[:n]}); err != nil {
+				return err
+			}
+			lines = lines[n:]
+		}
+		return nil
+	}
+
+	if err := send(t.initial); err != nil {
+		return err
+	}
+	if !in.GetFollow() {
+		return send(t.flush())
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		lines, err := t.poll()
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if err := send(lines); err != nil {
+			return err
+		}
+	}
+}
+
+// tailer follows a file by name the way tail -F does.
+type tailer struct {
+	path    string
+	f       *os.File
+	offset  int64
+	pending []byte
+	initial []string
+}
+
+// newTailer opens path and reads its last n lines, or as many of them as fit
+// in tailReadSize. An unterminated last line is held back until it's complete.
+func newTailer(path string, n int) (*tailer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	t := &tailer{path: path, f: f, offset: info.Size()}
+	if n <= 0 {
+		if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return t, nil
+	}
+
+	// Read backwards until there are enough newlines.
+	start := info.Size()
+	var data []byte
+	for start > 0 && len(data) < tailReadSize && bytes.Count(data, []byte{'\n'}) <= n {
+		size := int64(fileChunkSize)
+		if size > start {
+			size = start
+		}
+		start -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			f.Close()
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	lines := t.split(data)
+	if start > 0 && len(lines) > 0 {
+		// The first line was probably cut in half.
+		lines = lines[1:]
+	}
+	// The unterminated last line counts as one of the n, it is sent once
+	// complete or when not following.
+	if len(t.pending) > 0 {
+		n--
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	t.initial = lines
+	return t, nil
+}
+
+// poll returns the lines appended since the last call. It picks up the new
+// file once the old one has been rotated away and read to the end.
+func (t *tailer) poll() ([]string, error) {
+	lines, more, err := t.read()
+	if err != nil {
+		return nil, err
+	}
+	if more {
+		return lines, nil
+	}
+
+	info, err := t.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < t.offset {
+		log.WithField("name", t.path).Debug("File truncated, reading from the start")
+		if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		t.offset = 0
+		t.pending = nil
+		return lines, nil
+	}
+
+	current, err := os.Stat(t.path)
+	if err != nil || os.SameFile(info, current) {
+		// Keep reading the old file until the new one shows up.
+		return lines, nil
+	}
+
+	log.WithField("name", t.path).Debug("File rotated, reopening")
+	f, err := os.Open(t.path)
+	if err != nil {
+		return lines, nil
+	}
+	lines = append(lines, t.flush()...)
+	t.f.Close()
+	t.f = f
+	t.offset = 0
+	return lines, nil
+}
+
+// read returns the lines appended since the last call, reading at most
+// tailReadSize bytes. more reports whether that limit was hit.
+func (t *tailer) read() (lines []string, more bool, err error) {
+	data, err := io.ReadAll(io.LimitReader(t.f, tailReadSize))
+	t.offset += int64(len(data))
+	if err != nil {
+		return nil, false, err
+	}
+	return t.split(data), len(data) == tailReadSize, nil
+}
+
+// split appends data to the pending line and returns the complete lines.
+func (t *tailer) split(data []byte) []string {
+	t.pending = append(t.pending, data...)
+
+	var lines []string
+	for {
+		i := bytes.IndexByte(t.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := t.pending[:i]
+		if len(line) > maxLineLen {
+			line = line[:maxLineLen]
+		}
+		lines = append(lines, string(line))
+		t.pending = t.pending[i+1:]
+	}
+	if len(t.pending) > maxLineLen {
+		lines = append(lines, string(t.pending[:maxLineLen]))
+		t.pending = nil
+	}
+	return lines
+}
+
+// flush returns the unterminated last line, if any.
+func (t *tailer) flush() []string {
+	if len(t.pending) == 0 {
+		return nil
+	}
+	line := string(t.pending)
+	t.pending = nil
+	return []string{line}
+}
+
+func (t *tailer) Close() error {
+	return t.f.Close()
+}
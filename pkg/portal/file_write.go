@@ -0,0 +1,305 @@
+package portal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const defaultFileMode = 0644
+
+// writeOptions controls the metadata of files written by writeAtomic. A
+// zero mode and negative ids keep whatever the file had before, or fall back
+// to defaults for new files. A non-empty checksum is compared with the SHA-256
+// of the new content before it replaces the old one.
+type writeOptions struct {
+	mode     os.FileMode
+	uid      int
+	gid      int
+	backup   bool
+	checksum string
+}
+
+func (s *Server) FileWrite(stream portal.DRPCPortal_FileWriteStream) error {
+	header, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	fields := log.Fields{
+		"context": "file",
+		"command": "write",
+		"name":    header.GetPath(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received file write request: %d bytes", header.GetSize())
+
+	path, err := s.Paths.Resolve(header.GetPath())
+	if err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+	if err := s.authorize(stream.Context(), "FileWrite", path); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	uid, gid, err := lookupOwner(header.GetOwner(), header.GetGroup())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	opts := writeOptions{
+		mode:     os.FileMode(header.GetMode()).Perm(),
+		uid:      uid,
+		gid:      gid,
+		backup:   header.GetBackup(),
+		checksum: header.GetSha256(),
+	}
+
+	if sum, err := fileSHA256(path); err == nil && sum == header.GetSha256() {
+		changed, err := applyMetadata(path, opts)
+		if err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if changed {
+			return stream.Send(&portal.FileWriteResponse{State: portal.State_CHANGED, Message: "Content up to date, metadata updated"})
+		}
+		return stream.Send(&portal.FileWriteResponse{State: portal.State_UNCHANGED, Message: "File already up to date"})
+	}
+
+	dir := filepath.Dir(path)
+	if header.GetParents() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Error(err.Error())
+			return err
+		}
+	} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		err := fmt.Errorf("directory %s does not exist", dir)
+		log.Error(err.Error())
+		return err
+	}
+	if err := stream.Send(&portal.FileWriteResponse{Ready: true}); err != nil {
+		return err
+	}
+
+	content := &chunkReader{stream: stream}
+	if _, err := writeAtomic(path, content, opts); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	log.Debugf("Wrote %d bytes", content.n)
+	return stream.Send(&portal.FileWriteResponse{State: portal.State_CHANGED, Message: fmt.Sprintf("Wrote %d bytes", content.n)})
+}
+
+// chunkReader reads the chunks of a FileWrite stream until the client is
+// done sending.
+type chunkReader struct {
+	stream portal.DRPCPortal_FileWriteStream
+	buf    []byte
+	n      int64
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = msg.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.n += int64(n)
+	return n, nil
+}
+
+// writeAtomic writes content to a temporary file next to path and renames it
+// into place once everything made it to disk, so readers never see a partial
+// file. It returns the SHA-256 of what was written.
+func writeAtomic(path string, content io.Reader, opts writeOptions) (string, error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".speedrun-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if opts.checksum != "" && sum != opts.checksum {
+		return "", fmt.Errorf("checksum mismatch: expected %s, got %s", opts.checksum, sum)
+	}
+
+	mode, uid, gid := opts.mode, opts.uid, opts.gid
+	if current, err := os.Stat(path); err == nil {
+		if mode == 0 {
+			mode = current.Mode().Perm()
+		}
+		if st, ok := current.Sys().(*syscall.Stat_t); ok {
+			if uid < 0 {
+				uid = int(st.Uid)
+			}
+			if gid < 0 {
+				gid = int(st.Gid)
+			}
+		}
+	}
+	if mode == 0 {
+		mode = defaultFileMode
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := os.Chown(tmp.Name(), uid, gid); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return "", err
+	}
+
+	if opts.backup {
+		if err := backupFile(path); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// backupFile keeps a timestamped copy of path, if it exists, next to it.
+func backupFile(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102T150405"))
+	if err := os.Link(path, backup); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(backup, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// applyMetadata brings mode and ownership of an existing file in line with
+// opts and reports whether anything had to change.
+func applyMetadata(path string, opts writeOptions) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	if opts.mode != 0 && info.Mode().Perm() != opts.mode {
+		if err := os.Chmod(path, opts.mode); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		uid, gid := -1, -1
+		if opts.uid >= 0 && uint32(opts.uid) != st.Uid {
+			uid = opts.uid
+		}
+		if opts.gid >= 0 && uint32(opts.gid) != st.Gid {
+			gid = opts.gid
+		}
+		if uid >= 0 || gid >= 0 {
+			if err := os.Lchown(path, uid, gid); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// lookupOwner turns user and group names or numeric ids into ids, -1 stands
+// for empty.
+func lookupOwner(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if owner != "" {
+		if id, err := strconv.Atoi(owner); err == nil {
+			uid = id
+		} else {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return -1, -1, err
+			}
+			uid, _ = strconv.Atoi(u.Uid)
+		}
+	}
+
+	if group != "" {
+		if id, err := strconv.Atoi(group); err == nil {
+			gid = id
+		} else {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return -1, -1, err
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+
+	return uid, gid, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,474 @@
+package portal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var jobIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var errJobsDisabled = errors.New("background jobs are not enabled on this portal")
+
+// JobManager runs commands in the background and keeps their metadata and
+// output in a spool directory, one subdirectory per job, so that both survive
+// client disconnects and portal restarts. Finished jobs are removed once they
+// are older than the retention period.
+type JobManager struct {
+	dir       string
+	grace     time.Duration
+	retention time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+type job struct {
+	info   *portal.Job
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewJobManager creates the spool directory if needed and loads the jobs
+// found in it. Jobs that were still running when the portal went away are
+// marked as lost. A retention of 0 keeps finished jobs forever.
+func NewJobManager(dir string, grace, retention time.Duration) (*JobManager, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	m := &JobManager{
+		dir:       dir,
+		grace:     grace,
+		retention: retention,
+		jobs:      map[string]*job{},
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), "job.json"))
+		if err != nil {
+			log.Warnf("Skipping job %s: %s", e.Name(), err)
+			continue
+		}
+		info := &portal.Job{}
+		if err := protojson.Unmarshal(data, info); err != nil {
+			log.Warnf("Skipping job %s: %s", e.Name(), err)
+			continue
+		}
+
+		j := &job{info: info, done: make(chan struct{})}
+		close(j.done)
+		if info.GetState() == portal.JobState_PENDING || info.GetState() == portal.JobState_RUNNING {
+			info.State = portal.JobState_LOST
+			info.Error = "portal stopped while the job was running"
+			if err := m.save(info); err != nil {
+				log.Warnf("Couldn't update job %s: %s", info.GetId(), err)
+			}
+		}
+		m.jobs[info.GetId()] = j
+	}
+	m.prune()
+
+	return m, nil
+}
+
+// prune removes the finished jobs that are older than the retention period
+// along with their output, the caller must hold m.mu once the manager is in
+// use.
+func (m *JobManager) prune() {
+	if m.retention <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-m.retention).Unix()
+	for id, j := range m.jobs {
+		select {
+		case <-j.done:
+		default:
+			continue
+		}
+		finished := j.info.GetFinishedAt()
+		if finished == 0 {
+			// Lost jobs never got to finish.
+			finished = j.info.GetStartedAt()
+		}
+		if finished > cutoff {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(m.dir, id)); err != nil {
+			log.Warnf("Couldn't remove job %s: %s", id, err)
+			continue
+		}
+		delete(m.jobs, id)
+		log.WithField("job", id).Debug("Removed expired job")
+	}
+}
+
+// Submit starts name with args in the background. An empty id makes the
+// manager generate one.
+func (m *JobManager) Submit(id, name string, args []string) (*portal.Job, error) {
+	if id == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		id = hex.EncodeToString(b)
+	}
+	if !jobIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid job id: %q", id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	if _, ok := m.jobs[id]; ok {
+		return nil, fmt.Errorf("job %s already exists", id)
+	}
+
+	dir := filepath.Join(m.dir, id)
+	if err := os.Mkdir(dir, 0700); err != nil {
+		return nil, err
+	}
+	output, err := os.OpenFile(m.outputPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	info := &portal.Job{
+		Id:        id,
+		Name:      name,
+		Args:      args,
+		State:     portal.JobState_RUNNING,
+		StartedAt: time.Now().Unix(),
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{info: info, cancel: cancel, done: make(chan struct{})}
+	m.jobs[id] = j
+	if err := m.save(info); err != nil {
+		log.Warnf("Couldn't save job %s: %s", id, err)
+	}
+
+	go func() {
+		defer close(j.done)
+		defer output.Close()
+		defer cancel()
+
+		err := runProcess(ctx, cmd, m.grace)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		info.FinishedAt = time.Now().Unix()
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			info.State = portal.JobState_CANCELLED
+			info.ExitCode = -1
+		case err == nil:
+			info.State = portal.JobState_SUCCEEDED
+		case errors.As(err, &exitErr):
+			info.State = portal.JobState_FAILED
+			info.ExitCode = int32(exitErr.ExitCode())
+		default:
+			info.State = portal.JobState_FAILED
+			info.ExitCode = -1
+			info.Error = err.Error()
+		}
+		if err := m.save(info); err != nil {
+			log.Warnf("Couldn't save job %s: %s", id, err)
+		}
+		log.WithField("job", id).Debugf("Job finished: %s", info.GetState())
+	}()
+
+	return proto.Clone(info).(*portal.Job), nil
+}
+
+// Get returns a snapshot of the job with the given id.
+func (m *JobManager) Get(id string) (*portal.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return proto.Clone(j.info).(*portal.Job), nil
+}
+
+// List returns a snapshot of all known jobs, oldest first.
+func (m *JobManager) List() []*portal.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*portal.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, proto.Clone(j.info).(*portal.Job))
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].GetStartedAt() == jobs[b].GetStartedAt() {
+			return jobs[a].GetId() < jobs[b].GetId()
+		}
+		return jobs[a].GetStartedAt() < jobs[b].GetStartedAt()
+	})
+	return jobs
+}
+
+// Cancel stops a running job and waits for it to exit. It reports false if
+// the job had already finished.
+func (m *JobManager) Cancel(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return false, fmt.Errorf("job %s not found", id)
+	}
+
+	select {
+	case <-j.done:
+		return false, nil
+	default:
+	}
+
+	j.cancel()
+	select {
+	case <-j.done:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+// Follow copies the output of a job to w. With follow set it keeps going
+// until the job has finished and all of its output has been written.
+func (m *JobManager) Follow(ctx context.Context, id string, follow bool, w io.Writer) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+
+	f, err := os.Open(m.outputPath(id))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+
+		select {
+		case <-j.done:
+			_, err := io.Copy(w, f)
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *JobManager) outputPath(id string) string {
+	return filepath.Join(m.dir, id, "output.log")
+}
+
+// save persists the job metadata, the caller must hold m.mu for jobs that
+// are already registered.
+func (m *JobManager) save(info *portal.Job) error {
+	data, err := protojson.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.dir, info.GetId(), "job.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Server) JobSubmit(ctx context.Context, in *portal.JobSubmitRequest) (*portal.JobSubmitResponse, error) {
+	fields := log.Fields{
+		"context": "job",
+		"command": "submit",
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received job: %s %s", in.GetName(), in.GetArgs())
+	if err := s.authorize(ctx, "JobSubmit", in.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	if s.Jobs == nil {
+		return nil, errJobsDisabled
+	}
+	if err := s.Policy.Check(in.GetName(), in.GetArgs()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	job, err := s.Jobs.Submit(in.GetId(), in.GetName(), in.GetArgs())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.JobSubmitResponse{State: portal.State_CHANGED, Id: job.GetId()}, nil
+}
+
+func (s *Server) JobStatus(ctx context.Context, in *portal.JobRequest) (*portal.JobStatusResponse, error) {
+	fields := log.Fields{
+		"context": "job",
+		"command": "status",
+		"id":      in.GetId(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received job status request")
+	if s.Jobs == nil {
+		return nil, errJobsDisabled
+	}
+
+	job, err := s.Jobs.Get(in.GetId())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "JobStatus", job.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	return &portal.JobStatusResponse{Job: job}, nil
+}
+
+func (s *Server) JobLogs(in *portal.JobLogsRequest, stream portal.DRPCPortal_JobLogsStream) error {
+	fields := log.Fields{
+		"context": "job",
+		"command": "logs",
+		"id":      in.GetId(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received job logs request")
+	if s.Jobs == nil {
+		return errJobsDisabled
+	}
+
+	job, err := s.Jobs.Get(in.GetId())
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if err := s.authorize(stream.Context(), "JobLogs", job.GetName()); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	w := jobLogsWriter{stream}
+	err = s.Jobs.Follow(stream.Context(), in.GetId(), in.GetFollow(), w)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *Server) JobList(ctx context.Context, in *portal.JobListRequest) (*portal.JobListResponse, error) {
+	fields := log.Fields{
+		"context": "job",
+		"command": "list",
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received job list request")
+	if err := s.authorize(ctx, "JobList"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	if s.Jobs == nil {
+		return nil, errJobsDisabled
+	}
+
+	return &portal.JobListResponse{Jobs: s.Jobs.List()}, nil
+}
+
+func (s *Server) JobCancel(ctx context.Context, in *portal.JobRequest) (*portal.JobCancelResponse, error) {
+	fields := log.Fields{
+		"context": "job",
+		"command": "cancel",
+		"id":      in.GetId(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received job cancel request")
+	if s.Jobs == nil {
+		return nil, errJobsDisabled
+	}
+
+	job, err := s.Jobs.Get(in.GetId())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if err := s.authorize(ctx, "JobCancel", job.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	cancelled, err := s.Jobs.Cancel(ctx, in.GetId())
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if !cancelled {
+		return &portal.JobCancelResponse{State: portal.State_UNCHANGED, Message: "Job already finished"}, nil
+	}
+	return &portal.JobCancelResponse{State: portal.State_CHANGED, Message: "Job cancelled"}, nil
+}
+
+// jobLogsWriter sends everything written to it as JobLogs messages.
+type jobLogsWriter struct {
+	stream portal.DRPCPortal_JobLogsStream
+}
+
+func (w jobLogsWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := w.stream.Send(&portal.JobLogsResponse{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,182 @@
+package portal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// journalPriorities are the priority names journalctl accepts besides 0-7.
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// ServiceLogs streams journal entries of a unit by running journalctl. Since
+// and until take a duration relative to now, e.g. 10m, or anything journalctl
+// understands.
+func (s *Server) ServiceLogs(in *portal.ServiceLogsRequest, stream portal.DRPCPortal_ServiceLogsStream) error {
+	fields := log.Fields{
+		"context": "service",
+		"command": "logs",
+		"name":    in.GetName(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received service logs request")
+	if err := s.authorize(stream.Context(), "ServiceLogs", in.GetName()); err != nil {
+		log.Warn(err.Error())
+		return err
+	}
+
+	args, err := journalArgs(in)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var stderr bytes.Buffer
+	w := &journalWriter{stream: stream}
+	cmd := exec.Command("journalctl", args...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	err = runProcess(stream.Context(), cmd, s.GracePeriod)
+	if stream.Context().Err() != nil {
+		// The client went away, most likely done following.
+		return nil
+	}
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		log.Error(err.Error())
+		return err
+	}
+	return w.err
+}
+
+func journalArgs(in *portal.ServiceLogsRequest) ([]string, error) {
+	args := []string{"--unit", unitName(in.GetName()), "--output", "json", "--no-pager"}
+	if in.GetLines() > 0 {
+		args = append(args, "--lines", strconv.Itoa(int(in.GetLines())))
+	}
+	if p := in.GetPriority(); p != "" {
+		if !validPriority(p) {
+			return nil, fmt.Errorf("invalid priority %q", p)
+		}
+		args = append(args, "--priority", p)
+	}
+	if in.GetSince() != "" {
+		args = append(args, "--since="+journalTime(in.GetSince()))
+	}
+	if in.GetUntil() != "" {
+		args = append(args, "--until="+journalTime(in.GetUntil()))
+	}
+	if in.GetFollow() {
+		args = append(args, "--follow")
+	}
+	return args, nil
+}
+
+func validPriority(p string) bool {
+	for i, name := range journalPriorities {
+		if p == name || p == strconv.Itoa(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// journalTime turns a duration into a time relative to now journalctl
+// understands and passes everything else through.
+func journalTime(t string) string {
+	d, err := time.ParseDuration(t)
+	if err != nil {
+		return t
+	}
+	return fmt.Sprintf("-%ds", int64(d.Seconds()))
+}
+
+// journalWriter parses the JSON lines journalctl writes and sends them as
+// ServiceLogs messages.
+type journalWriter struct {
+	stream portal.DRPCPortal_ServiceLogsStream
+	buf    []byte
+	err    error
+}
+
+func (w *journalWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := w.buf[:i]
+		w.buf = w.buf[i+1:]
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry, err := parseJournalEntry(line)
+		if err != nil {
+			log.Debugf("Skipping journal entry: %s", err)
+			continue
+		}
+		if err := w.stream.Send(entry); err != nil {
+			w.err = err
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func parseJournalEntry(line []byte) (*portal.ServiceLogsResponse, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+
+	entry := &portal.ServiceLogsResponse{
+		Message:    journalField(fields, "MESSAGE"),
+		Unit:       journalField(fields, "_SYSTEMD_UNIT"),
+		Identifier: journalField(fields, "SYSLOG_IDENTIFIER"),
+		Priority:   6,
+	}
+	if ts, err := strconv.ParseInt(journalField(fields, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		entry.Timestamp = ts
+	}
+	if p, err := strconv.Atoi(journalField(fields, "PRIORITY")); err == nil {
+		entry.Priority = int32(p)
+	}
+	if pid, err := strconv.Atoi(journalField(fields, "_PID")); err == nil {
+		entry.Pid = int32(pid)
+	}
+	return entry, nil
+}
+
+// journalField returns a field of a journal entry as a string. Fields that
+// aren't valid UTF-8 come as an array of bytes.
+func journalField(fields map[string]json.RawMessage, name string) string {
+	raw, ok := fields[name]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var b []byte
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err == nil {
+		for _, i := range ints {
+			b = append(b, byte(i))
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,143 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathPolicy restricts the files clients may access to the Allow prefixes,
+// or anywhere if there are none, minus the Deny prefixes. Both are compared
+// against fully resolved paths so symlinks can't be used to get around them.
+type PathPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewPathPolicy resolves symlinks in the given prefixes.
+func NewPathPolicy(allow, deny []string) PathPolicy {
+	resolve := func(prefixes []string) []string {
+		resolved := make([]string, 0, len(prefixes))
+		for _, p := range prefixes {
+			if r, err := resolvePath(p); err == nil {
+				p = r
+			}
+			resolved = append(resolved, filepath.Clean(p))
+		}
+		return resolved
+	}
+	return PathPolicy{Allow: resolve(allow), Deny: resolve(deny)}
+}
+
+// Resolve returns the absolute, symlink free form of name if the policy lets
+// clients access it. Paths that don't exist yet are resolved through their
+// closest existing parent directory.
+func (p PathPolicy) Resolve(name string) (string, error) {
+	if !filepath.IsAbs(name) {
+		return "", fmt.Errorf("path must be absolute: %s", name)
+	}
+
+	resolved, err := resolvePath(name)
+	if err != nil {
+		return "", err
+	}
+	return resolved, p.check(resolved)
+}
+
+// ResolveLink is like Resolve but leaves the last element of name alone, for
+// operations that act on a symlink itself rather than on what it points to.
+func (p PathPolicy) ResolveLink(name string) (string, error) {
+	if !filepath.IsAbs(name) {
+		return "", fmt.Errorf("path must be absolute: %s", name)
+	}
+
+	name = filepath.Clean(name)
+	dir, err := resolvePath(filepath.Dir(name))
+	if err != nil {
+		return "", err
+	}
+	resolved := filepath.Join(dir, filepath.Base(name))
+	return resolved, p.check(resolved)
+}
+
+func (p PathPolicy) check(resolved string) error {
+	if withinAny(p.Deny, resolved) {
+		return fmt.Errorf("%w: access to %s is denied", errPermissionDenied, resolved)
+	}
+	if len(p.Allow) > 0 && !withinAny(p.Allow, resolved) {
+		return fmt.Errorf("%w: access to %s is not allowed", errPermissionDenied, resolved)
+	}
+	return nil
+}
+
+// Glob expands pattern and resolves every match like Resolve does. Matches
+// the policy denies are left out, it only fails if that leaves none. A
+// pattern without wildcards is resolved as is, whether it exists or not.
+func (p PathPolicy) Glob(pattern string) ([]string, error) {
+	if !isGlob(pattern) {
+		path, err := p.Resolve(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	if !filepath.IsAbs(pattern) {
+		return nil, fmt.Errorf("path must be absolute: %s", pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+
+	var denied error
+	paths := make([]string, 0, len(matches))
+	for _, m := range matches {
+		path, err := p.Resolve(m)
+		if errors.Is(err, errPermissionDenied) {
+			if denied == nil {
+				denied = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, denied
+	}
+	return paths, nil
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+func resolvePath(name string) (string, error) {
+	name = filepath.Clean(name)
+
+	resolved, err := filepath.EvalSymlinks(name)
+	if err == nil {
+		return filepath.Abs(resolved)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	parent := filepath.Dir(name)
+	if parent == name {
+		return "", err
+	}
+	dir, err := resolvePath(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(name)), nil
+}
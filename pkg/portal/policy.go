@@ -0,0 +1,153 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Command policy modes.
+const (
+	PolicyOpen      = "open"
+	PolicyAllowlist = "allowlist"
+	PolicyDenylist  = "denylist"
+	PolicyDisabled  = "disabled"
+)
+
+var errPermissionDenied = errors.New("permission denied")
+
+// CommandPolicy decides which commands clients may run on the portal.
+//
+// In allowlist mode a command has to match one of the allow rules, in
+// denylist mode it must not match any of the deny rules. Deny rules are
+// checked in allowlist mode too. The disabled mode refuses to run arbitrary
+// commands at all.
+type CommandPolicy struct {
+	Mode  string        `mapstructure:"mode"`
+	Allow []CommandRule `mapstructure:"allow"`
+	Deny  []CommandRule `mapstructure:"deny"`
+}
+
+// CommandRule matches a binary and, optionally, its arguments joined by
+// single spaces against a regular expression.
+type CommandRule struct {
+	Binary string `mapstructure:"binary"`
+	Args   string `mapstructure:"args"`
+
+	args *regexp.Regexp
+}
+
+// LoadCommandPolicy reads a command policy from a config file.
+func LoadCommandPolicy(path string) (*CommandPolicy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	policy := &CommandPolicy{}
+	if err := v.Unmarshal(policy); err != nil {
+		return nil, err
+	}
+
+	switch policy.Mode {
+	case "":
+		policy.Mode = PolicyOpen
+	case PolicyOpen, PolicyAllowlist, PolicyDenylist, PolicyDisabled:
+	default:
+		return nil, fmt.Errorf("unknown command policy mode: %s", policy.Mode)
+	}
+
+	for _, rules := range [][]CommandRule{policy.Allow, policy.Deny} {
+		for i := range rules {
+			if rules[i].Binary == "" {
+				return nil, fmt.Errorf("command policy rule without binary")
+			}
+			if rules[i].Args == "" {
+				continue
+			}
+			re, err := regexp.Compile(rules[i].Args)
+			if err != nil {
+				return nil, fmt.Errorf("invalid args pattern for %s: %v", rules[i].Binary, err)
+			}
+			rules[i].args = re
+		}
+	}
+
+	return policy, nil
+}
+
+// Check returns a permission denied error if the policy forbids running name
+// with args. A nil policy allows everything.
+func (p *CommandPolicy) Check(name string, args []string) error {
+	if p == nil || p.Mode == PolicyOpen {
+		return nil
+	}
+	if p.Mode == PolicyDisabled {
+		return fmt.Errorf("%w: running commands is disabled on this portal", errPermissionDenied)
+	}
+
+	binary := resolveBinary(name)
+	joined := strings.Join(args, " ")
+
+	for _, r := range p.Deny {
+		if r.matches(binary, joined) {
+			return fmt.Errorf("%w: %s is denied by the command policy", errPermissionDenied, name)
+		}
+	}
+
+	if p.Mode == PolicyDenylist {
+		return nil
+	}
+
+	for _, r := range p.Allow {
+		if r.matches(binary, joined) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not allowed by the command policy", errPermissionDenied, name)
+}
+
+// CheckScript returns a permission denied error unless the policy lets
+// clients run arbitrary scripts with the given interpreter. Scripts can do
+// anything, so only open and denylist policies allow them.
+func (p *CommandPolicy) CheckScript(interpreter string) error {
+	if p == nil || p.Mode == PolicyOpen {
+		return nil
+	}
+	if p.Mode != PolicyDenylist {
+		return fmt.Errorf("%w: running scripts is not allowed by the command policy", errPermissionDenied)
+	}
+	return p.Check(interpreter, nil)
+}
+
+func (r CommandRule) matches(binary, args string) bool {
+	if resolveBinary(r.Binary) != binary {
+		return false
+	}
+	return r.args == nil || r.args.MatchString(args)
+}
+
+// resolveBinary turns a command name into the absolute path of the file that
+// would be executed, so that rules can't be dodged with a different path to
+// the same binary or a lookalike earlier in $PATH. Only the directory is
+// resolved: multi-call binaries like busybox are symlinked under many names
+// and must not all match the same rule.
+func resolveBinary(name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return name
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(dir, filepath.Base(path))
+	}
+	return path
+}
@@ -0,0 +1,43 @@
+package portal
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// runProcess starts cmd in its own process group and waits for it to exit.
+// If ctx is done before that, the whole group receives SIGTERM and, if it is
+// still around once the grace period has passed, SIGKILL.
+func runProcess(ctx context.Context, cmd *exec.Cmd, grace time.Duration) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	pgid := cmd.Process.Pid
+	syscall.Kill(-pgid, syscall.SIGTERM)
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		syscall.Kill(-pgid, syscall.SIGKILL)
+		<-done
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,98 @@
+package portal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const defaultInterpreter = "/bin/sh"
+
+func (s *Server) RunScript(ctx context.Context, in *portal.ScriptRequest) (*portal.CommandResponse, error) {
+	sum := sha256.Sum256(in.GetContent())
+	checksum := hex.EncodeToString(sum[:])
+
+	fields := log.Fields{
+		"context": "script",
+		"name":    in.GetName(),
+		"sha256":  checksum,
+	}
+	log := log.WithFields(fields)
+	log.Infof("Received script: %s %s", in.GetName(), in.GetArgs())
+
+	if in.GetSha256() != "" && in.GetSha256() != checksum {
+		err := fmt.Errorf("script checksum mismatch: expected %s, got %s", in.GetSha256(), checksum)
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	interpreter := interpreterFor(in.GetContent())
+	if err := s.authorize(ctx, "RunScript", interpreter[0]); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+	if err := s.Policy.CheckScript(interpreter[0]); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	f, err := os.CreateTemp("", "speedrun-script-*")
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(in.GetContent())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	args := append([]string{}, interpreter[1:]...)
+	args = append(args, f.Name())
+	args = append(args, in.GetArgs()...)
+	log.Debugf("Running script with %s", strings.Join(interpreter, " "))
+
+	var output bytes.Buffer
+	cmd := exec.Command(interpreter[0], args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = runProcess(ctx, cmd, s.GracePeriod)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.CommandResponse{Message: strings.TrimSpace(output.String())}, nil
+}
+
+// interpreterFor returns the interpreter and its arguments from the shebang
+// line of a script, falling back to the default shell when there is none.
+func interpreterFor(content []byte) []string {
+	line, err := bufio.NewReader(bytes.NewReader(content)).ReadString('\n')
+	if err != nil && line == "" {
+		return []string{defaultInterpreter}
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return []string{defaultInterpreter}
+	}
+
+	interpreter := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(interpreter) == 0 {
+		return []string{defaultInterpreter}
+	}
+	return interpreter
+}
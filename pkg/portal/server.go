@@ -1,7 +1,36 @@
 package portal
 
-import "github.com/dpogorzelski/speedrun/proto/portal"
+import (
+	"time"
+
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// maxMessageSize is the most content put in a single response, drpc rejects
+// packets larger than 4 MiB and the rest of the message needs room too.
+const maxMessageSize = 3 * 1024 * 1024
 
 type Server struct {
 	portal.DRPCPortalUnimplementedServer
+
+	// GracePeriod is how long a cancelled command gets to exit after SIGTERM
+	// before its process group is killed.
+	GracePeriod time.Duration
+
+	// Jobs runs background jobs, they are disabled when it is nil.
+	Jobs *JobManager
+
+	// Policy restricts which commands clients may run, nil allows everything.
+	Policy *CommandPolicy
+
+	// Authz restricts which RPCs clients may call based on their certificate,
+	// nil allows everything.
+	Authz *Authorizer
+
+	// Paths restricts which files clients may access.
+	Paths PathPolicy
+
+	// MaxFileSize caps how many bytes of a file are returned, it is never
+	// more than fits in a single response.
+	MaxFileSize int64
 }
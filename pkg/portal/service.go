@@ -3,6 +3,7 @@ package portal
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/apex/log"
@@ -18,6 +19,10 @@ func (s *Server) ServiceRestart(ctx context.Context, service *portal.ServiceRequ
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received service restart request")
+	if err := s.authorize(ctx, "ServiceRestart", service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	conn, err := dbus.NewWithContext(ctx)
 	if err != nil {
@@ -27,7 +32,12 @@ func (s *Server) ServiceRestart(ctx context.Context, service *portal.ServiceRequ
 	defer conn.Close()
 
 	responseChan := make(chan string, 1)
-	serviceName := fmt.Sprintf("%s.service", service.GetName())
+	serviceName := unitName(service.GetName())
+	if _, err := findUnit(ctx, conn, serviceName); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
 	_, err = conn.RestartUnitContext(ctx, serviceName, "replace", responseChan)
 	if err != nil {
 		log.Error(err.Error())
@@ -36,7 +46,12 @@ func (s *Server) ServiceRestart(ctx context.Context, service *portal.ServiceRequ
 
 	res := <-responseChan
 	log.Debugf("Service restart result: %v", res)
-	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}, nil
+	resp, err := withWait(ctx, conn, serviceName, service, &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}, "active")
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (s *Server) ServiceStop(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
@@ -47,6 +62,10 @@ func (s *Server) ServiceStop(ctx context.Context, service *portal.ServiceRequest
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received service stop request")
+	if err := s.authorize(ctx, "ServiceStop", service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	conn, err := dbus.NewWithContext(ctx)
 	if err != nil {
@@ -56,14 +75,14 @@ func (s *Server) ServiceStop(ctx context.Context, service *portal.ServiceRequest
 	defer conn.Close()
 
 	responseChan := make(chan string, 1)
-	serviceName := fmt.Sprintf("%s.service", service.GetName())
-	list, err := conn.ListUnitsByNamesContext(ctx, []string{serviceName})
+	serviceName := unitName(service.GetName())
+	unit, err := findUnit(ctx, conn, serviceName)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	log.Debugf("Fetched service list by name: %v", list)
-	if list[0].ActiveState == "inactive" {
+	log.Debugf("Fetched service by name: %v", unit)
+	if unit.ActiveState == "inactive" {
 		return &portal.ServiceResponse{State: portal.State_UNCHANGED, Message: "Service already stopped"}, nil
 	}
 
@@ -75,7 +94,12 @@ func (s *Server) ServiceStop(ctx context.Context, service *portal.ServiceRequest
 
 	res := <-responseChan
 	log.Debugf("Service stop result: %v", res)
-	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}, nil
+	resp, err := withWait(ctx, conn, serviceName, service, &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}, "inactive", "failed")
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return resp, nil
 
 }
 
@@ -87,6 +111,10 @@ func (s *Server) ServiceStart(ctx context.Context, service *portal.ServiceReques
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received service start request")
+	if err := s.authorize(ctx, "ServiceStart", service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	conn, err := dbus.NewWithContext(ctx)
 	if err != nil {
@@ -96,26 +124,34 @@ func (s *Server) ServiceStart(ctx context.Context, service *portal.ServiceReques
 	defer conn.Close()
 
 	responseChan := make(chan string, 1)
-	serviceName := fmt.Sprintf("%s.service", service.GetName())
-	list, err := conn.ListUnitsByNamesContext(ctx, []string{serviceName})
+	serviceName := unitName(service.GetName())
+	unit, err := findUnit(ctx, conn, serviceName)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	log.Debugf("Fetched service list by name: %v", list)
-	if list[0].ActiveState == "active" {
-		return &portal.ServiceResponse{State: portal.State_UNCHANGED, Message: "Service already running"}, nil
+	log.Debugf("Fetched service by name: %v", unit)
+
+	resp := &portal.ServiceResponse{State: portal.State_UNCHANGED, Message: "Service already running"}
+	if unit.ActiveState != "active" {
+		_, err = conn.StartUnitContext(ctx, serviceName, "replace", responseChan)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+
+		res := <-responseChan
+		log.Debugf("Service start result: %v", res)
+		resp = &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}
 	}
 
-	_, err = conn.StartUnitContext(ctx, serviceName, "replace", responseChan)
+	// A running service may still have to pass its health check.
+	resp, err = withWait(ctx, conn, serviceName, service, resp, "active")
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-
-	res := <-responseChan
-	log.Debugf("Service start result: %v", res)
-	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.Title(res)}, nil
+	return resp, nil
 
 }
 
@@ -127,6 +163,10 @@ func (s *Server) ServiceStatus(ctx context.Context, service *portal.ServiceReque
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received service status request")
+	if err := s.authorize(ctx, "ServiceStatus", service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	conn, err := dbus.NewWithContext(ctx)
 	if err != nil {
@@ -135,24 +175,112 @@ func (s *Server) ServiceStatus(ctx context.Context, service *portal.ServiceReque
 	}
 	defer conn.Close()
 
-	serviceName := fmt.Sprintf("%s.service", service.GetName())
-	res, err := conn.ListUnitsByNamesContext(ctx, []string{serviceName})
+	serviceName := unitName(service.GetName())
+	unit, err := findUnit(ctx, conn, serviceName)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	log.Debugf("Fetched service list by name: %v", res[0])
+	log.Debugf("Fetched service by name: %v", unit)
+
+	res := &portal.ServiceStatusResponse{
+		State:       portal.State_UNCHANGED,
+		Activestate: unit.ActiveState,
+		Loadstate:   unit.LoadState,
+		Substate:    unit.SubState,
+		Description: unit.Description,
+	}
 
-	if res[0].LoadState == "not-found" {
-		log.Error("service not found")
-		return nil, fmt.Errorf("service not found")
+	// The details are best effort, not every unit type has all of them.
+	props, err := conn.GetUnitPropertiesContext(ctx, serviceName)
+	if err != nil {
+		log.Warnf("Could not fetch unit properties: %s", err)
+		return res, nil
 	}
+	res.UnitFileState = stringProperty(props, "UnitFileState")
+	res.ActiveSince = usecProperty(props, "ActiveEnterTimestamp")
 
-	return &portal.ServiceStatusResponse{
-		State:       portal.State_UNCHANGED,
-		Activestate: res[0].ActiveState,
-		Loadstate:   res[0].LoadState,
-		Substate:    res[0].SubState,
-	}, nil
+	iface := strings.TrimPrefix(path.Ext(serviceName), ".")
+	iface = strings.ToUpper(iface[:1]) + iface[1:]
+	props, err = conn.GetUnitTypePropertiesContext(ctx, serviceName, iface)
+	if err != nil {
+		log.Debugf("Could not fetch %s properties: %s", iface, err)
+		return res, nil
+	}
+	res.MainPid = uint32Property(props, "MainPID")
+	res.Restarts = uint32Property(props, "NRestarts")
+	res.Memory = uint64Property(props, "MemoryCurrent")
+	res.CpuNsec = uint64Property(props, "CPUUsageNSec")
+	return res, nil
+}
+
+func (s *Server) ServiceReload(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
+	fields := log.Fields{
+		"context": "service",
+		"command": "reload",
+		"name":    service.GetName(),
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received service reload request")
+	if err := s.authorize(ctx, "ServiceReload", service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	responseChan := make(chan string, 1)
+	serviceName := unitName(service.GetName())
+	if _, err := findUnit(ctx, conn, serviceName); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	_, err = conn.ReloadUnitContext(ctx, serviceName, "replace", responseChan)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	res := <-responseChan
+	log.Debugf("Service reload result: %v", res)
+	if res != "done" {
+		err := fmt.Errorf("%s reload job %s", serviceName, res)
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.ToUpper(res[:1]) + res[1:]}, nil
+}
+
+// ServiceDaemonReload reloads the systemd manager configuration, picking up
+// new and changed unit files.
+func (s *Server) ServiceDaemonReload(ctx context.Context, in *portal.ServiceDaemonReloadRequest) (*portal.ServiceResponse, error) {
+	fields := log.Fields{
+		"context": "service",
+		"command": "daemon-reload",
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received daemon reload request")
+	if err := s.authorize(ctx, "ServiceDaemonReload"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.ReloadContext(ctx); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: "Reloaded systemd configuration"}, nil
 }
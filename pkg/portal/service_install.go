@@ -0,0 +1,156 @@
+package portal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// unitDir is where installed unit files and drop-ins go.
+const unitDir = "/etc/systemd/system"
+
+// UnitInstall writes a unit file and makes systemd pick it up. Unit files
+// decide what runs as root, so unlike the Service RPCs it isn't granted by a
+// Service* role.
+func (s *Server) UnitInstall(ctx context.Context, in *portal.UnitInstallRequest) (*portal.ServiceResponse, error) {
+	return s.installUnit(ctx, "install", "UnitInstall", in, "")
+}
+
+// UnitOverride writes the override.conf drop-in of a unit and makes systemd
+// pick it up.
+func (s *Server) UnitOverride(ctx context.Context, in *portal.UnitInstallRequest) (*portal.ServiceResponse, error) {
+	return s.installUnit(ctx, "override", "UnitOverride", in, "override.conf")
+}
+
+// installUnit writes the unit file, or the drop-in of the unit when dropin
+// is set, reloads systemd if the content changed and optionally enables and
+// starts the unit. A running unit is restarted when systemd had to reload so it
+// picks up the new configuration.
+func (s *Server) installUnit(ctx context.Context, command, rpc string, in *portal.UnitInstallRequest, dropin string) (*portal.ServiceResponse, error) {
+	fields := log.Fields{
+		"context": "service",
+		"command": command,
+		"name":    in.GetName(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received service %s request: %d bytes", command, len(in.GetContent()))
+	if err := s.authorize(ctx, rpc, in.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	name := unitName(in.GetName())
+	if strings.ContainsRune(name, '/') || strings.HasPrefix(name, ".") {
+		err := fmt.Errorf("invalid unit name %q", in.GetName())
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	path := filepath.Join(unitDir, name)
+	if dropin != "" {
+		path = filepath.Join(unitDir, name+".d", dropin)
+	}
+	path, err := s.Paths.Resolve(path)
+	if err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	var done []string
+	sum := sha256.Sum256(in.GetContent())
+	if existing, err := fileSHA256(path); err != nil || existing != hex.EncodeToString(sum[:]) {
+		if err := os.MkdirAll(filepath.Dir(path), defaultDirMode); err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		opts := writeOptions{uid: -1, gid: -1}
+		if _, err := writeAtomic(path, bytes.NewReader(in.GetContent()), opts); err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		done = append(done, "wrote "+path)
+	}
+	written := len(done) > 0
+
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	// A previous attempt may have written the file but failed to reload.
+	reload := written
+	if !reload {
+		if p, err := conn.GetUnitPropertyContext(ctx, name, "NeedDaemonReload"); err == nil {
+			reload, _ = p.Value.Value().(bool)
+		}
+	}
+	if reload {
+		if err := conn.ReloadContext(ctx); err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		done = append(done, "reloaded systemd")
+	}
+
+	if in.GetEnable() {
+		_, changes, err := conn.EnableUnitFilesContext(ctx, []string{name}, false, false)
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		if len(changes) > 0 {
+			if err := conn.ReloadContext(ctx); err != nil {
+				log.Error(err.Error())
+				return nil, err
+			}
+			done = append(done, "enabled")
+		}
+	}
+
+	if in.GetStart() {
+		list, err := conn.ListUnitsByNamesContext(ctx, []string{name})
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		active := len(list) > 0 && list[0].ActiveState == "active"
+
+		responseChan := make(chan string, 1)
+		switch {
+		case !active:
+			_, err = conn.StartUnitContext(ctx, name, "replace", responseChan)
+			done = append(done, "started")
+		case reload:
+			_, err = conn.RestartUnitContext(ctx, name, "replace", responseChan)
+			done = append(done, "restarted")
+		default:
+			responseChan <- "done"
+		}
+		if err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		if res := <-responseChan; res != "done" {
+			err := fmt.Errorf("%s job %s", name, res)
+			log.Error(err.Error())
+			return nil, err
+		}
+	}
+
+	if len(done) == 0 {
+		return &portal.ServiceResponse{State: portal.State_UNCHANGED, Message: "Unit file already up to date"}, nil
+	}
+	msg := strings.Join(done, ", ")
+	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: strings.ToUpper(msg[:1]) + msg[1:]}, nil
+}
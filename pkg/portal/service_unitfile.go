@@ -0,0 +1,83 @@
+package portal
+
+import (
+	"context"
+
+	"github.com/apex/log"
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+func (s *Server) ServiceEnable(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
+	return s.unitFileChange(ctx, "enable", "ServiceEnable", service, "Service enabled", "Service already enabled",
+		func(conn *dbus.Conn, name string) (int, error) {
+			_, changes, err := conn.EnableUnitFilesContext(ctx, []string{name}, false, false)
+			return len(changes), err
+		})
+}
+
+func (s *Server) ServiceDisable(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
+	return s.unitFileChange(ctx, "disable", "ServiceDisable", service, "Service disabled", "Service already disabled",
+		func(conn *dbus.Conn, name string) (int, error) {
+			changes, err := conn.DisableUnitFilesContext(ctx, []string{name}, false)
+			return len(changes), err
+		})
+}
+
+func (s *Server) ServiceMask(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
+	return s.unitFileChange(ctx, "mask", "ServiceMask", service, "Service masked", "Service already masked",
+		func(conn *dbus.Conn, name string) (int, error) {
+			changes, err := conn.MaskUnitFilesContext(ctx, []string{name}, false, false)
+			return len(changes), err
+		})
+}
+
+func (s *Server) ServiceUnmask(ctx context.Context, service *portal.ServiceRequest) (*portal.ServiceResponse, error) {
+	return s.unitFileChange(ctx, "unmask", "ServiceUnmask", service, "Service unmasked", "Service not masked",
+		func(conn *dbus.Conn, name string) (int, error) {
+			changes, err := conn.UnmaskUnitFilesContext(ctx, []string{name}, false)
+			return len(changes), err
+		})
+}
+
+// unitFileChange runs change against the unit and reloads the manager
+// configuration afterwards, like systemctl does. systemd reports no changes
+// when the unit files are already in the desired state, which makes the
+// result UNCHANGED.
+func (s *Server) unitFileChange(ctx context.Context, command, rpc string, service *portal.ServiceRequest, changed, unchanged string, change func(conn *dbus.Conn, name string) (int, error)) (*portal.ServiceResponse, error) {
+	fields := log.Fields{
+		"context": "service",
+		"command": command,
+		"name":    service.GetName(),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received service %s request", command)
+	if err := s.authorize(ctx, rpc, service.GetName()); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	serviceName := unitName(service.GetName())
+	n, err := change(conn, serviceName)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	log.Debugf("Service %s changes: %d", command, n)
+	if n == 0 {
+		return &portal.ServiceResponse{State: portal.State_UNCHANGED, Message: unchanged}, nil
+	}
+
+	if err := conn.ReloadContext(ctx); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &portal.ServiceResponse{State: portal.State_CHANGED, Message: changed}, nil
+}
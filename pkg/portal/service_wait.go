@@ -0,0 +1,240 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const (
+	defaultWaitTimeout = 30 * time.Second
+	waitInterval       = 500 * time.Millisecond
+)
+
+var errUnitNotFound = errors.New("unit not found")
+
+// findUnit returns the status of a unit, or errUnitNotFound if systemd
+// doesn't know about it.
+func findUnit(ctx context.Context, conn *dbus.Conn, name string) (dbus.UnitStatus, error) {
+	list, err := conn.ListUnitsByNamesContext(ctx, []string{name})
+	if err != nil {
+		return dbus.UnitStatus{}, err
+	}
+	if len(list) == 0 || list[0].LoadState == "not-found" {
+		return dbus.UnitStatus{}, fmt.Errorf("%w: %s", errUnitNotFound, name)
+	}
+	return list[0], nil
+}
+
+// waitRequested reports whether the request asks to wait for the unit.
+func waitRequested(service *portal.ServiceRequest) bool {
+	return service.GetWaitActive() || service.GetHealthCheck() != ""
+}
+
+// waitUnit polls the unit until its active state is one of want and then,
+// if the request has a health check, until the check passes. It gives up
+// after the wait timeout of the request and returns a summary of what it
+// waited for.
+func waitUnit(ctx context.Context, conn *dbus.Conn, name string, service *portal.ServiceRequest, want ...string) (string, error) {
+	timeout := defaultWaitTimeout
+	if service.GetWaitTimeout() > 0 {
+		timeout = time.Duration(service.GetWaitTimeout()) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var summary string
+	if service.GetWaitActive() {
+		state, err := pollUnit(ctx, conn, name, want)
+		if err != nil {
+			return "", err
+		}
+		summary = fmt.Sprintf("%s after %s", state, time.Since(start).Round(time.Millisecond))
+	}
+
+	if check := service.GetHealthCheck(); check != "" {
+		if err := pollHealth(ctx, check); err != nil {
+			return "", err
+		}
+		if summary != "" {
+			summary += ", "
+		}
+		summary += fmt.Sprintf("healthy after %s", time.Since(start).Round(time.Millisecond))
+	}
+	return summary, nil
+}
+
+func pollUnit(ctx context.Context, conn *dbus.Conn, name string, want []string) (string, error) {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	for {
+		unit, err := findUnit(ctx, conn, name)
+		if err != nil {
+			return "", err
+		}
+		for _, w := range want {
+			if unit.ActiveState == w {
+				return unit.ActiveState, nil
+			}
+		}
+		if unit.ActiveState == "failed" {
+			return "", fmt.Errorf("%s failed: %s", name, unit.SubState)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("timed out waiting for %s to become %v, it is %s/%s", name, want, unit.ActiveState, unit.SubState)
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollHealth runs the health check until it passes. Checks are URLs, either
+// tcp://host:port which passes once the port accepts connections or an http
+// or https URL which passes once it responds with a 2xx or 3xx status.
+// Redirects aren't followed. Checks may only reach the host itself, so they
+// can't be used to probe the rest of the network.
+func pollHealth(ctx context.Context, check string) error {
+	u, err := url.Parse(check)
+	if err != nil {
+		return err
+	}
+	if err := checkLocal(ctx, u.Hostname()); err != nil {
+		return err
+	}
+
+	d := &net.Dialer{Control: dialLocal}
+	var probe func(ctx context.Context) error
+	switch u.Scheme {
+	case "tcp":
+		probe = func(ctx context.Context) error {
+			conn, err := d.DialContext(ctx, "tcp", u.Host)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}
+	case "http", "https":
+		client := &http.Client{
+			Transport: &http.Transport{DialContext: d.DialContext},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+		defer client.CloseIdleConnections()
+		probe = func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, check, nil)
+			if err != nil {
+				return err
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			if res.StatusCode >= 400 {
+				return fmt.Errorf("%s returned %s", check, res.Status)
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported health check %q, use tcp://, http:// or https://", check)
+	}
+
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		attempt, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := probe(attempt)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		// Report why the check failed rather than the deadline cutting
+		// the last attempt short.
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for health check: %v", lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkLocal returns an error unless host resolves to addresses of this host
+// only.
+func checkLocal(ctx context.Context, host string) error {
+	if host == "" {
+		return errors.New("health check has no host")
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if !isLocalIP(a.IP) {
+			return fmt.Errorf("%w: health checks may only target this host, not %s", errPermissionDenied, host)
+		}
+	}
+	return nil
+}
+
+// dialLocal refuses connections to other hosts, it catches names that
+// resolve differently by the time the probe connects.
+func dialLocal(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isLocalIP(ip) {
+		return fmt.Errorf("%w: health checks may only target this host, not %s", errPermissionDenied, host)
+	}
+	return nil
+}
+
+// isLocalIP reports whether ip is a loopback address or one of the addresses
+// of this host.
+func isLocalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// withWait waits for the unit as requested and adds what it waited for to
+// the message of res.
+func withWait(ctx context.Context, conn *dbus.Conn, name string, service *portal.ServiceRequest, res *portal.ServiceResponse, want ...string) (*portal.ServiceResponse, error) {
+	if !waitRequested(service) {
+		return res, nil
+	}
+	summary, err := waitUnit(ctx, conn, name, service, want...)
+	if err != nil {
+		return nil, err
+	}
+	res.Message += ", " + summary
+	return res, nil
+}
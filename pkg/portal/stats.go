@@ -0,0 +1,200 @@
+package portal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+const (
+	procDir = "/proc"
+
+	// cpuSampleInterval is how long CPU usage is measured over.
+	cpuSampleInterval = 250 * time.Millisecond
+)
+
+// CPUusage returns high level host stats read from /proc: load averages, CPU
+// utilization over a short interval, memory, swap and uptime.
+func (s *Server) CPUusage(ctx context.Context, in *portal.CPUusageRequest) (*portal.CPUusageResponse, error) {
+	fields := log.Fields{
+		"context": "system",
+		"command": "stats",
+	}
+	log := log.WithFields(fields)
+	log.Debug("Received CPU usage request")
+	if err := s.authorize(ctx, "CPUusage"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	res := &portal.CPUusageResponse{}
+	var err error
+	if res.Loadavg1, res.Loadavg5, res.Loadavg15, err = readLoadavg(); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if res.CpuPercent, res.Cpus, err = sampleCPU(ctx, cpuSampleInterval); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	mem, err := readMeminfo()
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	res.MemTotal = mem["MemTotal"]
+	res.MemAvailable = mem["MemAvailable"]
+	res.SwapTotal = mem["SwapTotal"]
+	res.SwapFree = mem["SwapFree"]
+	if res.Uptime, err = readUptime(); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	return res, nil
+}
+
+func readLoadavg() (float64, float64, float64, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, "loadavg"))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	f := strings.Fields(string(data))
+	if len(f) < 3 {
+		return 0, 0, 0, fmt.Errorf("unexpected loadavg format: %q", data)
+	}
+	var loads [3]float64
+	for i := range loads {
+		if loads[i], err = strconv.ParseFloat(f[i], 64); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return loads[0], loads[1], loads[2], nil
+}
+
+// cpuTimes holds the busy and total jiffies of all CPUs together.
+type cpuTimes struct {
+	busy, total uint64
+	cpus        int32
+}
+
+func readCPUTimes() (cpuTimes, error) {
+	f, err := os.Open(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	defer f.Close()
+
+	var t cpuTimes
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			t.cpus++
+			continue
+		}
+		// user nice system idle iowait irq softirq steal, guest time is
+		// already part of user and nice.
+		if len(fields) < 9 {
+			return cpuTimes{}, fmt.Errorf("unexpected stat format: %q", scanner.Text())
+		}
+		for i, v := range fields[1:9] {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return cpuTimes{}, err
+			}
+			t.total += n
+			if i != 3 && i != 4 {
+				t.busy += n
+			}
+		}
+		found = true
+	}
+	if err := scanner.Err(); err != nil {
+		return cpuTimes{}, err
+	}
+	if !found {
+		return cpuTimes{}, fmt.Errorf("no cpu line in %s", filepath.Join(procDir, "stat"))
+	}
+	return t, nil
+}
+
+// sampleCPU returns the CPU utilization in percent over the interval and the
+// number of CPUs.
+func sampleCPU(ctx context.Context, interval time.Duration) (float64, int32, error) {
+	before, err := readCPUTimes()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	case <-timer.C:
+	}
+
+	after, err := readCPUTimes()
+	if err != nil {
+		return 0, 0, err
+	}
+	if after.total <= before.total {
+		return 0, after.cpus, nil
+	}
+	return float64(after.busy-before.busy) / float64(after.total-before.total) * 100, after.cpus, nil
+}
+
+// readMeminfo returns the fields of /proc/meminfo in bytes.
+func readMeminfo() (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mem := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			n *= 1024
+		}
+		mem[name] = n
+	}
+	return mem, scanner.Err()
+}
+
+func readUptime() (float64, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+	f := strings.Fields(string(data))
+	if len(f) == 0 {
+		return 0, fmt.Errorf("unexpected uptime format: %q", data)
+	}
+	return strconv.ParseFloat(f[0], 64)
+}
@@ -17,6 +17,10 @@ func (s *Server) SystemReboot(ctx context.Context, system *portal.SystemRebootRe
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received system reboot request")
+	if err := s.authorize(ctx, "SystemReboot"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	syscall.Sync()
 	go syscall.Reboot(syscall.LINUX_REBOOT_CMD_RESTART)
@@ -31,6 +35,10 @@ func (s *Server) SystemShutdown(ctx context.Context, system *portal.SystemShutdo
 	}
 	log := log.WithFields(fields)
 	log.Debug("Received system shutdown request")
+	if err := s.authorize(ctx, "SystemShutdown"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
 
 	syscall.Sync()
 	go syscall.Reboot(syscall.LINUX_REBOOT_CMD_POWER_OFF)
@@ -0,0 +1,190 @@
+package portal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/dpogorzelski/speedrun/proto/portal"
+)
+
+// unitTypes are the unit name suffixes systemd knows about.
+var unitTypes = []string{"service", "socket", "device", "mount", "automount", "swap", "target", "path", "timer", "slice", "scope"}
+
+// unitName returns the full unit name for name, a name without a unit type
+// suffix is taken to be a service.
+func unitName(name string) string {
+	if isUnitType(strings.TrimPrefix(path.Ext(name), ".")) {
+		return name
+	}
+	return name + ".service"
+}
+
+func isUnitType(s string) bool {
+	for _, t := range unitTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceList lists the units matching any of the patterns that are in any
+// of the states, states match the load, active and sub state alike. No
+// patterns or states mean all of them. A type limits the list to units of
+// that type, timers, sockets and mounts come with their type specific details
+// such as the next elapse of timers or the listen addresses of sockets. Units
+// the caller may not act upon are left out.
+func (s *Server) ServiceList(ctx context.Context, in *portal.ServiceListRequest) (*portal.ServiceListResponse, error) {
+	fields := log.Fields{
+		"context": "service",
+		"command": "list",
+		"name":    strings.Join(in.GetPatterns(), ","),
+	}
+	log := log.WithFields(fields)
+	log.Debugf("Received service list request, type: %q, states: %v", in.GetType(), in.GetStates())
+	if err := s.authorize(ctx, "ServiceList"); err != nil {
+		log.Warn(err.Error())
+		return nil, err
+	}
+
+	patterns := in.GetPatterns()
+	if t := in.GetType(); t != "" {
+		if !isUnitType(t) {
+			err := fmt.Errorf("unsupported unit type %q", t)
+			log.Error(err.Error())
+			return nil, err
+		}
+		if len(patterns) == 0 {
+			patterns = []string{"*"}
+		}
+		typed := make([]string, 0, len(patterns))
+		for _, p := range patterns {
+			if !strings.HasSuffix(p, "."+t) {
+				p += "." + t
+			}
+			typed = append(typed, p)
+		}
+		patterns = typed
+	}
+
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	list, err := conn.ListUnitsByPatternsContext(ctx, in.GetStates(), patterns)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	log.Debugf("Fetched %d units", len(list))
+
+	res := &portal.ServiceListResponse{}
+	for _, u := range list {
+		// Roles limited to some services only get to see those.
+		if err := s.authorize(ctx, "ServiceList", u.Name); err != nil {
+			continue
+		}
+		info := &portal.UnitInfo{
+			Name:        u.Name,
+			Description: u.Description,
+			Loadstate:   u.LoadState,
+			Activestate: u.ActiveState,
+			Substate:    u.SubState,
+		}
+		res.Units = append(res.Units, info)
+		if err := unitDetails(ctx, conn, in.GetType(), info); err != nil {
+			log.WithField("unit", u.Name).Warnf("Could not fetch properties: %s", err)
+		}
+	}
+	return res, nil
+}
+
+// unitDetails fills in the type specific details of loaded timers, sockets and
+// mounts, other units are left alone.
+func unitDetails(ctx context.Context, conn *dbus.Conn, unitType string, info *portal.UnitInfo) error {
+	switch {
+	case info.Loadstate != "loaded":
+		return nil
+	case unitType == "timer", unitType == "socket", unitType == "mount":
+	default:
+		return nil
+	}
+
+	iface := strings.ToUpper(unitType[:1]) + unitType[1:]
+	props, err := conn.GetUnitTypePropertiesContext(ctx, info.Name, iface)
+	if err != nil {
+		return err
+	}
+	switch unitType {
+	case "timer":
+		info.Timer = &portal.TimerInfo{
+			NextElapse:  usecProperty(props, "NextElapseUSecRealtime"),
+			LastTrigger: usecProperty(props, "LastTriggerUSec"),
+			Unit:        stringProperty(props, "Unit"),
+		}
+	case "socket":
+		info.Socket = &portal.SocketInfo{
+			Listen:      listenProperty(props),
+			Accepted:    uint32Property(props, "NAccepted"),
+			Connections: uint32Property(props, "NConnections"),
+		}
+	case "mount":
+		info.Mount = &portal.MountInfo{
+			What:  stringProperty(props, "What"),
+			Where: stringProperty(props, "Where"),
+			Type:  stringProperty(props, "Type"),
+		}
+	}
+	return nil
+}
+
+func stringProperty(props map[string]interface{}, name string) string {
+	v, _ := props[name].(string)
+	return v
+}
+
+func uint32Property(props map[string]interface{}, name string) uint32 {
+	v, _ := props[name].(uint32)
+	return v
+}
+
+// uint64Property returns a counter property, systemd uses the maximum value
+// when accounting is off.
+func uint64Property(props map[string]interface{}, name string) uint64 {
+	v, _ := props[name].(uint64)
+	if v == ^uint64(0) {
+		return 0
+	}
+	return v
+}
+
+// usecProperty returns a timestamp property in microseconds since the epoch,
+// systemd uses 0 and the maximum value for "never".
+func usecProperty(props map[string]interface{}, name string) int64 {
+	v, _ := props[name].(uint64)
+	if v == 0 || v == ^uint64(0) {
+		return 0
+	}
+	return int64(v)
+}
+
+// listenProperty returns the listen addresses of a socket, systemd reports
+// them as (type, address) pairs.
+func listenProperty(props map[string]interface{}) []string {
+	var listen []string
+	pairs, _ := props["Listen"].([][]interface{})
+	for _, p := range pairs {
+		if len(p) != 2 {
+			continue
+		}
+		listen = append(listen, fmt.Sprintf("%v (%v)", p[1], p[0]))
+	}
+	return listen
+}
@@ -43,7 +43,7 @@ func GetInstances(target string) ([]Instance, error) {
 		return nil, err
 	}
 
-	subset, err := filter(instances, target)
+	subset, err := Filter(instances, target)
 	if len(subset) == 0 {
 		return nil, fmt.Errorf("no instances found")
 	}
@@ -71,7 +71,9 @@ func SetupTLS() (*tls.Config, error) {
 
 }
 
-func filter(instnces []Instance, target string) ([]Instance, error) {
+// Filter returns the instances matching the target expression, all of them
+// when target is empty.
+func Filter(instnces []Instance, target string) ([]Instance, error) {
 	if target == "" {
 		return instnces, nil
 	}
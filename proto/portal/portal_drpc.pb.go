@@ -42,11 +42,40 @@ type DRPCPortalClient interface {
 	ServiceStart(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
 	ServiceStop(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
 	ServiceStatus(ctx context.Context, in *ServiceRequest) (*ServiceStatusResponse, error)
+	ServiceEnable(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
+	ServiceDisable(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
+	ServiceMask(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
+	ServiceUnmask(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
+	ServiceReload(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error)
+	ServiceDaemonReload(ctx context.Context, in *ServiceDaemonReloadRequest) (*ServiceResponse, error)
+	ServiceList(ctx context.Context, in *ServiceListRequest) (*ServiceListResponse, error)
+	ServiceLogs(ctx context.Context, in *ServiceLogsRequest) (DRPCPortal_ServiceLogsClient, error)
+	UnitInstall(ctx context.Context, in *UnitInstallRequest) (*ServiceResponse, error)
+	UnitOverride(ctx context.Context, in *UnitInstallRequest) (*ServiceResponse, error)
 	RunCommand(ctx context.Context, in *CommandRequest) (*CommandResponse, error)
+	RunScript(ctx context.Context, in *ScriptRequest) (*CommandResponse, error)
 	CPUusage(ctx context.Context, in *CPUusageRequest) (*CPUusageResponse, error)
 	FileRead(ctx context.Context, in *FileReadRequest) (*FileReadResponse, error)
+	FileWrite(ctx context.Context) (DRPCPortal_FileWriteClient, error)
+	FilePull(ctx context.Context, in *FilePullRequest) (DRPCPortal_FilePullClient, error)
+	FileTail(ctx context.Context, in *FileTailRequest) (DRPCPortal_FileTailClient, error)
+	FileGrep(ctx context.Context, in *FileGrepRequest) (*FileGrepResponse, error)
+	FileStat(ctx context.Context, in *FileStatRequest) (*FileStatResponse, error)
+	FileManifest(ctx context.Context, in *FileManifestRequest) (DRPCPortal_FileManifestClient, error)
+	FileRemove(ctx context.Context, in *FileRemoveRequest) (*FileRemoveResponse, error)
+	FileEnsureLine(ctx context.Context, in *FileEnsureLineRequest) (*FileEditResponse, error)
+	FileEnsureBlock(ctx context.Context, in *FileEnsureBlockRequest) (*FileEditResponse, error)
+	FileChmod(ctx context.Context, in *FileChmodRequest) (*FileEditResponse, error)
+	FileChown(ctx context.Context, in *FileChownRequest) (*FileEditResponse, error)
+	FileMkdir(ctx context.Context, in *FileMkdirRequest) (*FileEditResponse, error)
+	FileMove(ctx context.Context, in *FileMoveRequest) (*FileEditResponse, error)
 	SystemReboot(ctx context.Context, in *SystemRebootRequest) (*SystemRebootResponse, error)
 	SystemShutdown(ctx context.Context, in *SystemShutdownRequest) (*SystemShutdownResponse, error)
+	JobSubmit(ctx context.Context, in *JobSubmitRequest) (*JobSubmitResponse, error)
+	JobStatus(ctx context.Context, in *JobRequest) (*JobStatusResponse, error)
+	JobLogs(ctx context.Context, in *JobLogsRequest) (DRPCPortal_JobLogsClient, error)
+	JobList(ctx context.Context, in *JobListRequest) (*JobListResponse, error)
+	JobCancel(ctx context.Context, in *JobRequest) (*JobCancelResponse, error)
 }
 
 type drpcPortalClient struct {
@@ -95,303 +124,1512 @@ func (c *drpcPortalClient) ServiceStatus(ctx context.Context, in *ServiceRequest
 	return out, nil
 }
 
+func (c *drpcPortalClient) ServiceEnable(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceEnable", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceDisable(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceDisable", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceMask(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceMask", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceUnmask(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceUnmask", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceReload(ctx context.Context, in *ServiceRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceReload", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceDaemonReload(ctx context.Context, in *ServiceDaemonReloadRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceDaemonReload", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceList(ctx context.Context, in *ServiceListRequest) (*ServiceListResponse, error) {
+	out := new(ServiceListResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/ServiceList", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) ServiceLogs(ctx context.Context, in *ServiceLogsRequest) (DRPCPortal_ServiceLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/ServiceLogs", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_ServiceLogsClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCPortal_ServiceLogsClient interface {
+	drpc.Stream
+	Recv() (*ServiceLogsResponse, error)
+}
+
+type drpcPortal_ServiceLogsClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceLogsClient) Recv() (*ServiceLogsResponse, error) {
+	m := new(ServiceLogsResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_ServiceLogsClient) RecvMsg(m *ServiceLogsResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) UnitInstall(ctx context.Context, in *UnitInstallRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/UnitInstall", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) UnitOverride(ctx context.Context, in *UnitInstallRequest) (*ServiceResponse, error) {
+	out := new(ServiceResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/UnitOverride", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *drpcPortalClient) RunCommand(ctx context.Context, in *CommandRequest) (*CommandResponse, error) {
 	out := new(CommandResponse)
 	err := c.cc.Invoke(ctx, "/portal.Portal/RunCommand", drpcEncoding_File_portal_portal_proto{}, in, out)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *drpcPortalClient) RunScript(ctx context.Context, in *ScriptRequest) (*CommandResponse, error) {
+	out := new(CommandResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/RunScript", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) CPUusage(ctx context.Context, in *CPUusageRequest) (*CPUusageResponse, error) {
+	out := new(CPUusageResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/CPUusage", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileRead(ctx context.Context, in *FileReadRequest) (*FileReadResponse, error) {
+	out := new(FileReadResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileRead", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileWrite(ctx context.Context) (DRPCPortal_FileWriteClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/FileWrite", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_FileWriteClient{stream}
+	return x, nil
+}
+
+type DRPCPortal_FileWriteClient interface {
+	drpc.Stream
+	Send(*FileWriteRequest) error
+	Recv() (*FileWriteResponse, error)
+}
+
+type drpcPortal_FileWriteClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FileWriteClient) Send(m *FileWriteRequest) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (x *drpcPortal_FileWriteClient) Recv() (*FileWriteResponse, error) {
+	m := new(FileWriteResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_FileWriteClient) RecvMsg(m *FileWriteResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) FilePull(ctx context.Context, in *FilePullRequest) (DRPCPortal_FilePullClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/FilePull", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_FilePullClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCPortal_FilePullClient interface {
+	drpc.Stream
+	Recv() (*FilePullResponse, error)
+}
+
+type drpcPortal_FilePullClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FilePullClient) Recv() (*FilePullResponse, error) {
+	m := new(FilePullResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_FilePullClient) RecvMsg(m *FilePullResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) FileTail(ctx context.Context, in *FileTailRequest) (DRPCPortal_FileTailClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/FileTail", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_FileTailClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCPortal_FileTailClient interface {
+	drpc.Stream
+	Recv() (*FileTailResponse, error)
+}
+
+type drpcPortal_FileTailClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FileTailClient) Recv() (*FileTailResponse, error) {
+	m := new(FileTailResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_FileTailClient) RecvMsg(m *FileTailResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) FileGrep(ctx context.Context, in *FileGrepRequest) (*FileGrepResponse, error) {
+	out := new(FileGrepResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileGrep", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileStat(ctx context.Context, in *FileStatRequest) (*FileStatResponse, error) {
+	out := new(FileStatResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileStat", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileManifest(ctx context.Context, in *FileManifestRequest) (DRPCPortal_FileManifestClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/FileManifest", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_FileManifestClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCPortal_FileManifestClient interface {
+	drpc.Stream
+	Recv() (*FileManifestResponse, error)
+}
+
+type drpcPortal_FileManifestClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FileManifestClient) Recv() (*FileManifestResponse, error) {
+	m := new(FileManifestResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_FileManifestClient) RecvMsg(m *FileManifestResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) FileRemove(ctx context.Context, in *FileRemoveRequest) (*FileRemoveResponse, error) {
+	out := new(FileRemoveResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileRemove", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileEnsureLine(ctx context.Context, in *FileEnsureLineRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileEnsureLine", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileEnsureBlock(ctx context.Context, in *FileEnsureBlockRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileEnsureBlock", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileChmod(ctx context.Context, in *FileChmodRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileChmod", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileChown(ctx context.Context, in *FileChownRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileChown", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileMkdir(ctx context.Context, in *FileMkdirRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileMkdir", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) FileMove(ctx context.Context, in *FileMoveRequest) (*FileEditResponse, error) {
+	out := new(FileEditResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/FileMove", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) SystemReboot(ctx context.Context, in *SystemRebootRequest) (*SystemRebootResponse, error) {
+	out := new(SystemRebootResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/SystemReboot", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) SystemShutdown(ctx context.Context, in *SystemShutdownRequest) (*SystemShutdownResponse, error) {
+	out := new(SystemShutdownResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/SystemShutdown", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) JobSubmit(ctx context.Context, in *JobSubmitRequest) (*JobSubmitResponse, error) {
+	out := new(JobSubmitResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/JobSubmit", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) JobStatus(ctx context.Context, in *JobRequest) (*JobStatusResponse, error) {
+	out := new(JobStatusResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/JobStatus", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) JobLogs(ctx context.Context, in *JobLogsRequest) (DRPCPortal_JobLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/portal.Portal/JobLogs", drpcEncoding_File_portal_portal_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcPortal_JobLogsClient{stream}
+	if err := x.MsgSend(in, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DRPCPortal_JobLogsClient interface {
+	drpc.Stream
+	Recv() (*JobLogsResponse, error)
+}
+
+type drpcPortal_JobLogsClient struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobLogsClient) Recv() (*JobLogsResponse, error) {
+	m := new(JobLogsResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcPortal_JobLogsClient) RecvMsg(m *JobLogsResponse) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+func (c *drpcPortalClient) JobList(ctx context.Context, in *JobListRequest) (*JobListResponse, error) {
+	out := new(JobListResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/JobList", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPortalClient) JobCancel(ctx context.Context, in *JobRequest) (*JobCancelResponse, error) {
+	out := new(JobCancelResponse)
+	err := c.cc.Invoke(ctx, "/portal.Portal/JobCancel", drpcEncoding_File_portal_portal_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type DRPCPortalServer interface {
+	ServiceRestart(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceStart(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceStop(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceStatus(context.Context, *ServiceRequest) (*ServiceStatusResponse, error)
+	ServiceEnable(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceDisable(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceMask(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceUnmask(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceReload(context.Context, *ServiceRequest) (*ServiceResponse, error)
+	ServiceDaemonReload(context.Context, *ServiceDaemonReloadRequest) (*ServiceResponse, error)
+	ServiceList(context.Context, *ServiceListRequest) (*ServiceListResponse, error)
+	ServiceLogs(*ServiceLogsRequest, DRPCPortal_ServiceLogsStream) error
+	UnitInstall(context.Context, *UnitInstallRequest) (*ServiceResponse, error)
+	UnitOverride(context.Context, *UnitInstallRequest) (*ServiceResponse, error)
+	RunCommand(context.Context, *CommandRequest) (*CommandResponse, error)
+	RunScript(context.Context, *ScriptRequest) (*CommandResponse, error)
+	CPUusage(context.Context, *CPUusageRequest) (*CPUusageResponse, error)
+	FileRead(context.Context, *FileReadRequest) (*FileReadResponse, error)
+	FileWrite(DRPCPortal_FileWriteStream) error
+	FilePull(*FilePullRequest, DRPCPortal_FilePullStream) error
+	FileTail(*FileTailRequest, DRPCPortal_FileTailStream) error
+	FileGrep(context.Context, *FileGrepRequest) (*FileGrepResponse, error)
+	FileStat(context.Context, *FileStatRequest) (*FileStatResponse, error)
+	FileManifest(*FileManifestRequest, DRPCPortal_FileManifestStream) error
+	FileRemove(context.Context, *FileRemoveRequest) (*FileRemoveResponse, error)
+	FileEnsureLine(context.Context, *FileEnsureLineRequest) (*FileEditResponse, error)
+	FileEnsureBlock(context.Context, *FileEnsureBlockRequest) (*FileEditResponse, error)
+	FileChmod(context.Context, *FileChmodRequest) (*FileEditResponse, error)
+	FileChown(context.Context, *FileChownRequest) (*FileEditResponse, error)
+	FileMkdir(context.Context, *FileMkdirRequest) (*FileEditResponse, error)
+	FileMove(context.Context, *FileMoveRequest) (*FileEditResponse, error)
+	SystemReboot(context.Context, *SystemRebootRequest) (*SystemRebootResponse, error)
+	SystemShutdown(context.Context, *SystemShutdownRequest) (*SystemShutdownResponse, error)
+	JobSubmit(context.Context, *JobSubmitRequest) (*JobSubmitResponse, error)
+	JobStatus(context.Context, *JobRequest) (*JobStatusResponse, error)
+	JobLogs(*JobLogsRequest, DRPCPortal_JobLogsStream) error
+	JobList(context.Context, *JobListRequest) (*JobListResponse, error)
+	JobCancel(context.Context, *JobRequest) (*JobCancelResponse, error)
+}
+
+type DRPCPortalUnimplementedServer struct{}
+
+func (s *DRPCPortalUnimplementedServer) ServiceRestart(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceStart(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceStop(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceStatus(context.Context, *ServiceRequest) (*ServiceStatusResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceEnable(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceDisable(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceMask(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceUnmask(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceReload(context.Context, *ServiceRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceDaemonReload(context.Context, *ServiceDaemonReloadRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceList(context.Context, *ServiceListRequest) (*ServiceListResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) ServiceLogs(*ServiceLogsRequest, DRPCPortal_ServiceLogsStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) UnitInstall(context.Context, *UnitInstallRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) UnitOverride(context.Context, *UnitInstallRequest) (*ServiceResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) RunCommand(context.Context, *CommandRequest) (*CommandResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) RunScript(context.Context, *ScriptRequest) (*CommandResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) CPUusage(context.Context, *CPUusageRequest) (*CPUusageResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileRead(context.Context, *FileReadRequest) (*FileReadResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileWrite(DRPCPortal_FileWriteStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FilePull(*FilePullRequest, DRPCPortal_FilePullStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileTail(*FileTailRequest, DRPCPortal_FileTailStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileGrep(context.Context, *FileGrepRequest) (*FileGrepResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileStat(context.Context, *FileStatRequest) (*FileStatResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileManifest(*FileManifestRequest, DRPCPortal_FileManifestStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileRemove(context.Context, *FileRemoveRequest) (*FileRemoveResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileEnsureLine(context.Context, *FileEnsureLineRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileEnsureBlock(context.Context, *FileEnsureBlockRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileChmod(context.Context, *FileChmodRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileChown(context.Context, *FileChownRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileMkdir(context.Context, *FileMkdirRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) FileMove(context.Context, *FileMoveRequest) (*FileEditResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) SystemReboot(context.Context, *SystemRebootRequest) (*SystemRebootResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) SystemShutdown(context.Context, *SystemShutdownRequest) (*SystemShutdownResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) JobSubmit(context.Context, *JobSubmitRequest) (*JobSubmitResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) JobStatus(context.Context, *JobRequest) (*JobStatusResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) JobLogs(*JobLogsRequest, DRPCPortal_JobLogsStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) JobList(context.Context, *JobListRequest) (*JobListResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPortalUnimplementedServer) JobCancel(context.Context, *JobRequest) (*JobCancelResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+type DRPCPortalDescription struct{}
+
+func (DRPCPortalDescription) NumMethods() int { return 38 }
+
+func (DRPCPortalDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
+	switch n {
+	case 0:
+		return "/portal.Portal/ServiceRestart", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceRestart(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceRestart, true
+	case 1:
+		return "/portal.Portal/ServiceStart", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceStart(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceStart, true
+	case 2:
+		return "/portal.Portal/ServiceStop", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceStop(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceStop, true
+	case 3:
+		return "/portal.Portal/ServiceStatus", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceStatus(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceStatus, true
+	case 4:
+		return "/portal.Portal/ServiceEnable", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceEnable(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceEnable, true
+	case 5:
+		return "/portal.Portal/ServiceDisable", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceDisable(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceDisable, true
+	case 6:
+		return "/portal.Portal/ServiceMask", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceMask(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceMask, true
+	case 7:
+		return "/portal.Portal/ServiceUnmask", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceUnmask(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceUnmask, true
+	case 8:
+		return "/portal.Portal/ServiceReload", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceReload(
+						ctx,
+						in1.(*ServiceRequest),
+					)
+			}, DRPCPortalServer.ServiceReload, true
+	case 9:
+		return "/portal.Portal/ServiceDaemonReload", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceDaemonReload(
+						ctx,
+						in1.(*ServiceDaemonReloadRequest),
+					)
+			}, DRPCPortalServer.ServiceDaemonReload, true
+	case 10:
+		return "/portal.Portal/ServiceList", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					ServiceList(
+						ctx,
+						in1.(*ServiceListRequest),
+					)
+			}, DRPCPortalServer.ServiceList, true
+	case 11:
+		return "/portal.Portal/ServiceLogs", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					ServiceLogs(
+						in1.(*ServiceLogsRequest),
+						&drpcPortal_ServiceLogsStream{in2.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.ServiceLogs, true
+	case 12:
+		return "/portal.Portal/UnitInstall", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					UnitInstall(
+						ctx,
+						in1.(*UnitInstallRequest),
+					)
+			}, DRPCPortalServer.UnitInstall, true
+	case 13:
+		return "/portal.Portal/UnitOverride", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					UnitOverride(
+						ctx,
+						in1.(*UnitInstallRequest),
+					)
+			}, DRPCPortalServer.UnitOverride, true
+	case 14:
+		return "/portal.Portal/RunCommand", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					RunCommand(
+						ctx,
+						in1.(*CommandRequest),
+					)
+			}, DRPCPortalServer.RunCommand, true
+	case 15:
+		return "/portal.Portal/RunScript", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					RunScript(
+						ctx,
+						in1.(*ScriptRequest),
+					)
+			}, DRPCPortalServer.RunScript, true
+	case 16:
+		return "/portal.Portal/CPUusage", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					CPUusage(
+						ctx,
+						in1.(*CPUusageRequest),
+					)
+			}, DRPCPortalServer.CPUusage, true
+	case 17:
+		return "/portal.Portal/FileRead", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileRead(
+						ctx,
+						in1.(*FileReadRequest),
+					)
+			}, DRPCPortalServer.FileRead, true
+	case 18:
+		return "/portal.Portal/FileWrite", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					FileWrite(
+						&drpcPortal_FileWriteStream{in1.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.FileWrite, true
+	case 19:
+		return "/portal.Portal/FilePull", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					FilePull(
+						in1.(*FilePullRequest),
+						&drpcPortal_FilePullStream{in2.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.FilePull, true
+	case 20:
+		return "/portal.Portal/FileTail", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					FileTail(
+						in1.(*FileTailRequest),
+						&drpcPortal_FileTailStream{in2.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.FileTail, true
+	case 21:
+		return "/portal.Portal/FileGrep", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileGrep(
+						ctx,
+						in1.(*FileGrepRequest),
+					)
+			}, DRPCPortalServer.FileGrep, true
+	case 22:
+		return "/portal.Portal/FileStat", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileStat(
+						ctx,
+						in1.(*FileStatRequest),
+					)
+			}, DRPCPortalServer.FileStat, true
+	case 23:
+		return "/portal.Portal/FileManifest", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					FileManifest(
+						in1.(*FileManifestRequest),
+						&drpcPortal_FileManifestStream{in2.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.FileManifest, true
+	case 24:
+		return "/portal.Portal/FileRemove", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileRemove(
+						ctx,
+						in1.(*FileRemoveRequest),
+					)
+			}, DRPCPortalServer.FileRemove, true
+	case 25:
+		return "/portal.Portal/FileEnsureLine", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileEnsureLine(
+						ctx,
+						in1.(*FileEnsureLineRequest),
+					)
+			}, DRPCPortalServer.FileEnsureLine, true
+	case 26:
+		return "/portal.Portal/FileEnsureBlock", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileEnsureBlock(
+						ctx,
+						in1.(*FileEnsureBlockRequest),
+					)
+			}, DRPCPortalServer.FileEnsureBlock, true
+	case 27:
+		return "/portal.Portal/FileChmod", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileChmod(
+						ctx,
+						in1.(*FileChmodRequest),
+					)
+			}, DRPCPortalServer.FileChmod, true
+	case 28:
+		return "/portal.Portal/FileChown", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileChown(
+						ctx,
+						in1.(*FileChownRequest),
+					)
+			}, DRPCPortalServer.FileChown, true
+	case 29:
+		return "/portal.Portal/FileMkdir", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileMkdir(
+						ctx,
+						in1.(*FileMkdirRequest),
+					)
+			}, DRPCPortalServer.FileMkdir, true
+	case 30:
+		return "/portal.Portal/FileMove", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					FileMove(
+						ctx,
+						in1.(*FileMoveRequest),
+					)
+			}, DRPCPortalServer.FileMove, true
+	case 31:
+		return "/portal.Portal/SystemReboot", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					SystemReboot(
+						ctx,
+						in1.(*SystemRebootRequest),
+					)
+			}, DRPCPortalServer.SystemReboot, true
+	case 32:
+		return "/portal.Portal/SystemShutdown", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					SystemShutdown(
+						ctx,
+						in1.(*SystemShutdownRequest),
+					)
+			}, DRPCPortalServer.SystemShutdown, true
+	case 33:
+		return "/portal.Portal/JobSubmit", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					JobSubmit(
+						ctx,
+						in1.(*JobSubmitRequest),
+					)
+			}, DRPCPortalServer.JobSubmit, true
+	case 34:
+		return "/portal.Portal/JobStatus", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					JobStatus(
+						ctx,
+						in1.(*JobRequest),
+					)
+			}, DRPCPortalServer.JobStatus, true
+	case 35:
+		return "/portal.Portal/JobLogs", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCPortalServer).
+					JobLogs(
+						in1.(*JobLogsRequest),
+						&drpcPortal_JobLogsStream{in2.(drpc.Stream)},
+					)
+			}, DRPCPortalServer.JobLogs, true
+	case 36:
+		return "/portal.Portal/JobList", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					JobList(
+						ctx,
+						in1.(*JobListRequest),
+					)
+			}, DRPCPortalServer.JobList, true
+	case 37:
+		return "/portal.Portal/JobCancel", drpcEncoding_File_portal_portal_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPortalServer).
+					JobCancel(
+						ctx,
+						in1.(*JobRequest),
+					)
+			}, DRPCPortalServer.JobCancel, true
+	default:
+		return "", nil, nil, nil, false
+	}
+}
+
+func DRPCRegisterPortal(mux drpc.Mux, impl DRPCPortalServer) error {
+	return mux.Register(impl, DRPCPortalDescription{})
+}
+
+type DRPCPortal_ServiceRestartStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceRestartStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceRestartStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceStartStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceStartStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceStartStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceStopStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceStopStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceStopStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceStatusStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceStatusResponse) error
+}
+
+type drpcPortal_ServiceStatusStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceStatusStream) SendAndClose(m *ServiceStatusResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceEnableStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceEnableStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceEnableStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceDisableStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceDisableStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceDisableStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceMaskStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceMaskStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceMaskStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceUnmaskStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceUnmaskStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceUnmaskStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceReloadStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceReloadStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceReloadStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceDaemonReloadStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_ServiceDaemonReloadStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceDaemonReloadStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceListStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceListResponse) error
+}
+
+type drpcPortal_ServiceListStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceListStream) SendAndClose(m *ServiceListResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_ServiceLogsStream interface {
+	drpc.Stream
+	Send(*ServiceLogsResponse) error
+}
+
+type drpcPortal_ServiceLogsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_ServiceLogsStream) Send(m *ServiceLogsResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+type DRPCPortal_UnitInstallStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_UnitInstallStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_UnitInstallStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_UnitOverrideStream interface {
+	drpc.Stream
+	SendAndClose(*ServiceResponse) error
+}
+
+type drpcPortal_UnitOverrideStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_UnitOverrideStream) SendAndClose(m *ServiceResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_RunCommandStream interface {
+	drpc.Stream
+	SendAndClose(*CommandResponse) error
+}
+
+type drpcPortal_RunCommandStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_RunCommandStream) SendAndClose(m *CommandResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_RunScriptStream interface {
+	drpc.Stream
+	SendAndClose(*CommandResponse) error
+}
+
+type drpcPortal_RunScriptStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_RunScriptStream) SendAndClose(m *CommandResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_CPUusageStream interface {
+	drpc.Stream
+	SendAndClose(*CPUusageResponse) error
+}
+
+type drpcPortal_CPUusageStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_CPUusageStream) SendAndClose(m *CPUusageResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_FileReadStream interface {
+	drpc.Stream
+	SendAndClose(*FileReadResponse) error
+}
+
+type drpcPortal_FileReadStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FileReadStream) SendAndClose(m *FileReadResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
 }
 
-func (c *drpcPortalClient) CPUusage(ctx context.Context, in *CPUusageRequest) (*CPUusageResponse, error) {
-	out := new(CPUusageResponse)
-	err := c.cc.Invoke(ctx, "/portal.Portal/CPUusage", drpcEncoding_File_portal_portal_proto{}, in, out)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type DRPCPortal_FileWriteStream interface {
+	drpc.Stream
+	Send(*FileWriteResponse) error
+	Recv() (*FileWriteRequest, error)
 }
 
-func (c *drpcPortalClient) FileRead(ctx context.Context, in *FileReadRequest) (*FileReadResponse, error) {
-	out := new(FileReadResponse)
-	err := c.cc.Invoke(ctx, "/portal.Portal/FileRead", drpcEncoding_File_portal_portal_proto{}, in, out)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type drpcPortal_FileWriteStream struct {
+	drpc.Stream
 }
 
-func (c *drpcPortalClient) SystemReboot(ctx context.Context, in *SystemRebootRequest) (*SystemRebootResponse, error) {
-	out := new(SystemRebootResponse)
-	err := c.cc.Invoke(ctx, "/portal.Portal/SystemReboot", drpcEncoding_File_portal_portal_proto{}, in, out)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (x *drpcPortal_FileWriteStream) Send(m *FileWriteResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
 }
 
-func (c *drpcPortalClient) SystemShutdown(ctx context.Context, in *SystemShutdownRequest) (*SystemShutdownResponse, error) {
-	out := new(SystemShutdownResponse)
-	err := c.cc.Invoke(ctx, "/portal.Portal/SystemShutdown", drpcEncoding_File_portal_portal_proto{}, in, out)
-	if err != nil {
+func (x *drpcPortal_FileWriteStream) Recv() (*FileWriteRequest, error) {
+	m := new(FileWriteRequest)
+	if err := x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return m, nil
 }
 
-type DRPCPortalServer interface {
-	ServiceRestart(context.Context, *ServiceRequest) (*ServiceResponse, error)
-	ServiceStart(context.Context, *ServiceRequest) (*ServiceResponse, error)
-	ServiceStop(context.Context, *ServiceRequest) (*ServiceResponse, error)
-	ServiceStatus(context.Context, *ServiceRequest) (*ServiceStatusResponse, error)
-	RunCommand(context.Context, *CommandRequest) (*CommandResponse, error)
-	CPUusage(context.Context, *CPUusageRequest) (*CPUusageResponse, error)
-	FileRead(context.Context, *FileReadRequest) (*FileReadResponse, error)
-	SystemReboot(context.Context, *SystemRebootRequest) (*SystemRebootResponse, error)
-	SystemShutdown(context.Context, *SystemShutdownRequest) (*SystemShutdownResponse, error)
+func (x *drpcPortal_FileWriteStream) RecvMsg(m *FileWriteRequest) error {
+	return x.MsgRecv(m, drpcEncoding_File_portal_portal_proto{})
 }
 
-type DRPCPortalUnimplementedServer struct{}
-
-func (s *DRPCPortalUnimplementedServer) ServiceRestart(context.Context, *ServiceRequest) (*ServiceResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type DRPCPortal_FilePullStream interface {
+	drpc.Stream
+	Send(*FilePullResponse) error
 }
 
-func (s *DRPCPortalUnimplementedServer) ServiceStart(context.Context, *ServiceRequest) (*ServiceResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type drpcPortal_FilePullStream struct {
+	drpc.Stream
 }
 
-func (s *DRPCPortalUnimplementedServer) ServiceStop(context.Context, *ServiceRequest) (*ServiceResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+func (x *drpcPortal_FilePullStream) Send(m *FilePullResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
 }
 
-func (s *DRPCPortalUnimplementedServer) ServiceStatus(context.Context, *ServiceRequest) (*ServiceStatusResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type DRPCPortal_FileTailStream interface {
+	drpc.Stream
+	Send(*FileTailResponse) error
 }
 
-func (s *DRPCPortalUnimplementedServer) RunCommand(context.Context, *CommandRequest) (*CommandResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type drpcPortal_FileTailStream struct {
+	drpc.Stream
 }
 
-func (s *DRPCPortalUnimplementedServer) CPUusage(context.Context, *CPUusageRequest) (*CPUusageResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+func (x *drpcPortal_FileTailStream) Send(m *FileTailResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
 }
 
-func (s *DRPCPortalUnimplementedServer) FileRead(context.Context, *FileReadRequest) (*FileReadResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type DRPCPortal_FileGrepStream interface {
+	drpc.Stream
+	SendAndClose(*FileGrepResponse) error
 }
 
-func (s *DRPCPortalUnimplementedServer) SystemReboot(context.Context, *SystemRebootRequest) (*SystemRebootResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+type drpcPortal_FileGrepStream struct {
+	drpc.Stream
 }
 
-func (s *DRPCPortalUnimplementedServer) SystemShutdown(context.Context, *SystemShutdownRequest) (*SystemShutdownResponse, error) {
-	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+func (x *drpcPortal_FileGrepStream) SendAndClose(m *FileGrepResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
 }
 
-type DRPCPortalDescription struct{}
+type DRPCPortal_FileStatStream interface {
+	drpc.Stream
+	SendAndClose(*FileStatResponse) error
+}
 
-func (DRPCPortalDescription) NumMethods() int { return 9 }
+type drpcPortal_FileStatStream struct {
+	drpc.Stream
+}
 
-func (DRPCPortalDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
-	switch n {
-	case 0:
-		return "/portal.Portal/ServiceRestart", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					ServiceRestart(
-						ctx,
-						in1.(*ServiceRequest),
-					)
-			}, DRPCPortalServer.ServiceRestart, true
-	case 1:
-		return "/portal.Portal/ServiceStart", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					ServiceStart(
-						ctx,
-						in1.(*ServiceRequest),
-					)
-			}, DRPCPortalServer.ServiceStart, true
-	case 2:
-		return "/portal.Portal/ServiceStop", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					ServiceStop(
-						ctx,
-						in1.(*ServiceRequest),
-					)
-			}, DRPCPortalServer.ServiceStop, true
-	case 3:
-		return "/portal.Portal/ServiceStatus", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					ServiceStatus(
-						ctx,
-						in1.(*ServiceRequest),
-					)
-			}, DRPCPortalServer.ServiceStatus, true
-	case 4:
-		return "/portal.Portal/RunCommand", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					RunCommand(
-						ctx,
-						in1.(*CommandRequest),
-					)
-			}, DRPCPortalServer.RunCommand, true
-	case 5:
-		return "/portal.Portal/CPUusage", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					CPUusage(
-						ctx,
-						in1.(*CPUusageRequest),
-					)
-			}, DRPCPortalServer.CPUusage, true
-	case 6:
-		return "/portal.Portal/FileRead", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					FileRead(
-						ctx,
-						in1.(*FileReadRequest),
-					)
-			}, DRPCPortalServer.FileRead, true
-	case 7:
-		return "/portal.Portal/SystemReboot", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					SystemReboot(
-						ctx,
-						in1.(*SystemRebootRequest),
-					)
-			}, DRPCPortalServer.SystemReboot, true
-	case 8:
-		return "/portal.Portal/SystemShutdown", drpcEncoding_File_portal_portal_proto{},
-			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
-				return srv.(DRPCPortalServer).
-					SystemShutdown(
-						ctx,
-						in1.(*SystemShutdownRequest),
-					)
-			}, DRPCPortalServer.SystemShutdown, true
-	default:
-		return "", nil, nil, nil, false
+func (x *drpcPortal_FileStatStream) SendAndClose(m *FileStatResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
 	}
+	return x.CloseSend()
 }
 
-func DRPCRegisterPortal(mux drpc.Mux, impl DRPCPortalServer) error {
-	return mux.Register(impl, DRPCPortalDescription{})
+type DRPCPortal_FileManifestStream interface {
+	drpc.Stream
+	Send(*FileManifestResponse) error
 }
 
-type DRPCPortal_ServiceRestartStream interface {
+type drpcPortal_FileManifestStream struct {
 	drpc.Stream
-	SendAndClose(*ServiceResponse) error
 }
 
-type drpcPortal_ServiceRestartStream struct {
+func (x *drpcPortal_FileManifestStream) Send(m *FileManifestResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+type DRPCPortal_FileRemoveStream interface {
 	drpc.Stream
+	SendAndClose(*FileRemoveResponse) error
 }
 
-func (x *drpcPortal_ServiceRestartStream) SendAndClose(m *ServiceResponse) error {
+type drpcPortal_FileRemoveStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_FileRemoveStream) SendAndClose(m *FileRemoveResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_ServiceStartStream interface {
+type DRPCPortal_FileEnsureLineStream interface {
 	drpc.Stream
-	SendAndClose(*ServiceResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_ServiceStartStream struct {
+type drpcPortal_FileEnsureLineStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_ServiceStartStream) SendAndClose(m *ServiceResponse) error {
+func (x *drpcPortal_FileEnsureLineStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_ServiceStopStream interface {
+type DRPCPortal_FileEnsureBlockStream interface {
 	drpc.Stream
-	SendAndClose(*ServiceResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_ServiceStopStream struct {
+type drpcPortal_FileEnsureBlockStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_ServiceStopStream) SendAndClose(m *ServiceResponse) error {
+func (x *drpcPortal_FileEnsureBlockStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_ServiceStatusStream interface {
+type DRPCPortal_FileChmodStream interface {
 	drpc.Stream
-	SendAndClose(*ServiceStatusResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_ServiceStatusStream struct {
+type drpcPortal_FileChmodStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_ServiceStatusStream) SendAndClose(m *ServiceStatusResponse) error {
+func (x *drpcPortal_FileChmodStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_RunCommandStream interface {
+type DRPCPortal_FileChownStream interface {
 	drpc.Stream
-	SendAndClose(*CommandResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_RunCommandStream struct {
+type drpcPortal_FileChownStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_RunCommandStream) SendAndClose(m *CommandResponse) error {
+func (x *drpcPortal_FileChownStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_CPUusageStream interface {
+type DRPCPortal_FileMkdirStream interface {
 	drpc.Stream
-	SendAndClose(*CPUusageResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_CPUusageStream struct {
+type drpcPortal_FileMkdirStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_CPUusageStream) SendAndClose(m *CPUusageResponse) error {
+func (x *drpcPortal_FileMkdirStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
 	return x.CloseSend()
 }
 
-type DRPCPortal_FileReadStream interface {
+type DRPCPortal_FileMoveStream interface {
 	drpc.Stream
-	SendAndClose(*FileReadResponse) error
+	SendAndClose(*FileEditResponse) error
 }
 
-type drpcPortal_FileReadStream struct {
+type drpcPortal_FileMoveStream struct {
 	drpc.Stream
 }
 
-func (x *drpcPortal_FileReadStream) SendAndClose(m *FileReadResponse) error {
+func (x *drpcPortal_FileMoveStream) SendAndClose(m *FileEditResponse) error {
 	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
 		return err
 	}
@@ -429,3 +1667,80 @@ func (x *drpcPortal_SystemShutdownStream) SendAndClose(m *SystemShutdownResponse
 	}
 	return x.CloseSend()
 }
+
+type DRPCPortal_JobSubmitStream interface {
+	drpc.Stream
+	SendAndClose(*JobSubmitResponse) error
+}
+
+type drpcPortal_JobSubmitStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobSubmitStream) SendAndClose(m *JobSubmitResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_JobStatusStream interface {
+	drpc.Stream
+	SendAndClose(*JobStatusResponse) error
+}
+
+type drpcPortal_JobStatusStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobStatusStream) SendAndClose(m *JobStatusResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_JobLogsStream interface {
+	drpc.Stream
+	Send(*JobLogsResponse) error
+}
+
+type drpcPortal_JobLogsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobLogsStream) Send(m *JobLogsResponse) error {
+	return x.MsgSend(m, drpcEncoding_File_portal_portal_proto{})
+}
+
+type DRPCPortal_JobListStream interface {
+	drpc.Stream
+	SendAndClose(*JobListResponse) error
+}
+
+type drpcPortal_JobListStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobListStream) SendAndClose(m *JobListResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPortal_JobCancelStream interface {
+	drpc.Stream
+	SendAndClose(*JobCancelResponse) error
+}
+
+type drpcPortal_JobCancelStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPortal_JobCancelStream) SendAndClose(m *JobCancelResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_portal_portal_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}